@@ -0,0 +1,171 @@
+package iris
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// timeTypeOf returns DATE or TIME for fields of these types, the driver
+// can not read nor write them as time.Time
+func (dialector Dialector) timeTypeOf(field *schema.Field) string {
+	if field == nil || field.DBName == "" {
+		return ""
+	}
+	switch dataType := strings.ToUpper(dialector.DataTypeOf(field)); {
+	case dataType == "DATE":
+		return "DATE"
+	case dataType == "TIME" || strings.HasPrefix(dataType, "TIME("):
+		return "TIME"
+	}
+	return ""
+}
+
+// selectedAs returns the expression selecting a column of the field, when
+// it can not be read as is: streams are returned as OIDs, DATE and TIME
+// are not read as time.Time
+func (dialector Dialector) selectedAs(field *schema.Field) (prefix, suffix string, ok bool) {
+	if dialector.isStream(field) {
+		return "SUBSTRING(", ",1," + strconv.Itoa(maxStringLength) + ")", true
+	}
+	switch dialector.timeTypeOf(field) {
+	case "DATE":
+		return "CAST(", " AS TIMESTAMP)", true
+	case "TIME":
+		return "CAST({fn CONCAT('1970-01-01 ',CAST(", " AS VARCHAR(20)))} AS TIMESTAMP)", true
+	}
+	return "", "", false
+}
+
+// convertedFieldOf returns the field of the model a selected column is,
+// when the column has to be converted
+func (dialector Dialector) convertedFieldOf(stmt *gorm.Statement, column clause.Column) *schema.Field {
+	if stmt == nil || stmt.Schema == nil || column.Raw {
+		return nil
+	}
+	if column.Table != "" && column.Table != clause.CurrentTable && column.Table != stmt.Table {
+		return nil
+	}
+	if field := stmt.Schema.LookUpField(column.Name); field != nil {
+		if _, _, ok := dialector.selectedAs(field); ok {
+			return field
+		}
+	}
+	return nil
+}
+
+// writeConvertedColumn writes a column converted as selectedAs tells
+func (dialector Dialector) writeConvertedColumn(builder clause.Builder, column clause.Column, field *schema.Field) {
+	prefix, suffix, _ := dialector.selectedAs(field)
+	alias := column.Alias
+	if alias == "" {
+		alias = column.Name
+	}
+	column.Alias = ""
+	builder.WriteString(prefix)
+	builder.WriteQuoted(column)
+	builder.WriteString(suffix)
+	builder.WriteString(" AS ")
+	builder.WriteQuoted(alias)
+}
+
+// writeConvertedColumns writes all columns of the model, instead of *,
+// when some of them have to be converted, and returns false otherwise
+func (dialector Dialector) writeConvertedColumns(builder clause.Builder) bool {
+	stmt, ok := builder.(*gorm.Statement)
+	if !ok || stmt.Schema == nil || stmt.Schema.Table != stmt.Table {
+		return false
+	}
+
+	var convert bool
+	for _, field := range stmt.Schema.Fields {
+		if _, _, ok := dialector.selectedAs(field); ok {
+			convert = true
+			break
+		}
+	}
+	if !convert {
+		return false
+	}
+
+	for idx, name := range stmt.Schema.DBNames {
+		if idx > 0 {
+			builder.WriteByte(',')
+		}
+		column := clause.Column{Table: clause.CurrentTable, Name: name}
+		if field := stmt.Schema.FieldsByDBName[name]; dialector.convertedFieldOf(stmt, column) != nil {
+			dialector.writeConvertedColumn(builder, column, field)
+		} else {
+			builder.WriteQuoted(column)
+		}
+	}
+	return true
+}
+
+// castTimeValue returns the value of a DATE or TIME column, cast from its
+// wall clock, so the date does not change with the time zone
+func (dialector Dialector) castTimeValue(field *schema.Field, value interface{}) interface{} {
+	timeType := dialector.timeTypeOf(field)
+	if timeType == "" {
+		return value
+	}
+
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v == nil {
+			return value
+		}
+		t = *v
+	default:
+		return value
+	}
+
+	layout := "2006-01-02"
+	if timeType == "TIME" {
+		layout = "15:04:05.999999999"
+	}
+	return clause.Expr{SQL: "CAST(? AS " + timeType + ")", Vars: []interface{}{t.Format(layout)}}
+}
+
+// buildValues writes VALUES clause, with DATE and TIME values cast
+func (dialector Dialector) buildValues(c clause.Clause, builder clause.Builder) {
+	stmt, ok := builder.(*gorm.Statement)
+	values, isValues := c.Expression.(clause.Values)
+	if ok && isValues && stmt.Schema != nil {
+		rows := make([][]interface{}, len(values.Values))
+		for i, row := range values.Values {
+			rows[i] = make([]interface{}, len(row))
+			for j, value := range row {
+				if j < len(values.Columns) {
+					value = dialector.castTimeValue(stmt.Schema.LookUpField(values.Columns[j].Name), value)
+				}
+				rows[i][j] = value
+			}
+		}
+		values.Values = rows
+		c.Expression = values
+	}
+	c.Build(builder)
+}
+
+// buildSet writes SET clause, with DATE and TIME values cast
+func (dialector Dialector) buildSet(c clause.Clause, builder clause.Builder) {
+	stmt, ok := builder.(*gorm.Statement)
+	set, isSet := c.Expression.(clause.Set)
+	if ok && isSet && stmt.Schema != nil {
+		assignments := make(clause.Set, len(set))
+		for i, assignment := range set {
+			assignment.Value = dialector.castTimeValue(stmt.Schema.LookUpField(assignment.Column.Name), assignment.Value)
+			assignments[i] = assignment
+		}
+		c.Expression = assignments
+	}
+	c.Build(builder)
+}
@@ -0,0 +1,430 @@
+package iris
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Create replaces gorm:create callback.
+// IRIS does not accept multi-row VALUES, so every row of a batch is sent
+// as its own INSERT on the same connection, and the identity of each row
+// is read back right after it is inserted.
+// IRIS has no ON CONFLICT either, anything but a full-row upsert, which is
+// done by INSERT OR UPDATE, is emulated per row with an UPDATE issued when
+// the INSERT fails on a duplicated key.
+// Nor RETURNING, the returned columns of each inserted row are selected by
+// its RowID.
+// In dry run mode the SQL of a batch is the INSERT of every row, separated
+// by semicolons, as they are run.
+func Create(config *callbacks.Config) func(db *gorm.DB) {
+	create := callbacks.Create(config)
+
+	return func(db *gorm.DB) {
+		if db.Error != nil || db.Statement.SQL.Len() > 0 {
+			create(db)
+			return
+		}
+
+		if db.Statement.Schema != nil && !db.Statement.Unscoped {
+			for _, c := range db.Statement.Schema.CreateClauses {
+				db.Statement.AddClause(c)
+			}
+		}
+
+		db.Statement.AddClauseIfNotExists(clause.Insert{})
+		values := callbacks.ConvertToCreateValues(db.Statement)
+		if db.Error != nil {
+			return
+		}
+
+		// INSERT OR UPDATE of Config.InsertOrUpdate, or of a full-row upsert,
+		// updates rows on duplicated keys by itself
+		onConflict, _ := onConflictOf(db.Statement)
+		if isInsertOrUpdate(onConflict) {
+			onConflict = clause.OnConflict{}
+		}
+
+		if db.DryRun && len(values.Values) > 1 {
+			for idx, row := range values.Values {
+				if idx > 0 {
+					db.Statement.WriteString("; ")
+				}
+				db.Statement.AddClause(clause.Values{Columns: values.Columns, Values: [][]interface{}{row}})
+				db.Statement.Build(db.Statement.BuildClauses...)
+			}
+			return
+		}
+
+		_, returning := returningOf(db.Statement)
+		if len(values.Values) <= 1 && (db.DryRun || !onConflict.DoNothing && len(onConflict.DoUpdates) == 0 && !hasGeneratedKey(db.Statement) && !returning) {
+			db.Statement.AddClause(values)
+			db.Statement.Build(db.Statement.BuildClauses...)
+			create(db)
+			return
+		}
+
+		createRows(db, values, onConflict)
+	}
+}
+
+// onConflictOf returns ON CONFLICT clause of the statement, if any.
+func onConflictOf(stmt *gorm.Statement) (clause.OnConflict, bool) {
+	if c, ok := stmt.Clauses["ON CONFLICT"]; ok {
+		onConflict, ok := c.Expression.(clause.OnConflict)
+		return onConflict, ok
+	}
+	return clause.OnConflict{}, false
+}
+
+// isInsertOrUpdate reports whether onConflict is a full-row upsert, which
+// IRIS does natively with INSERT OR UPDATE.
+func isInsertOrUpdate(onConflict clause.OnConflict) bool {
+	return onConflict.UpdateAll && !onConflict.DoNothing && len(onConflict.Where.Exprs) == 0
+}
+
+// insertsOrUpdates reports whether the statement is an INSERT OR UPDATE,
+// for a full-row upsert, or for Config.InsertOrUpdate when the statement
+// has no ON CONFLICT clause of its own.
+func (dialector Dialector) insertsOrUpdates(stmt *gorm.Statement) bool {
+	if onConflict, ok := onConflictOf(stmt); ok {
+		return isInsertOrUpdate(onConflict)
+	}
+	return dialector.Config != nil && dialector.InsertOrUpdate
+}
+
+func createRows(db *gorm.DB, values clause.Values, onConflict clause.OnConflict) {
+	var (
+		stmt         = db.Statement
+		pkField      *schema.Field
+		pkFieldName  = "@id"
+		mapValues    []map[string]interface{}
+		rowsAffected int64
+	)
+
+	if stmt.Schema != nil {
+		if field := stmt.Schema.PrioritizedPrimaryField; field != nil && field.HasDefaultValue && field.Readable {
+			pkField = field
+			pkFieldName = field.DBName
+		}
+	}
+
+	switch dest := stmt.Dest.(type) {
+	case map[string]interface{}:
+		mapValues = []map[string]interface{}{dest}
+	case *map[string]interface{}:
+		mapValues = []map[string]interface{}{*dest}
+	case []map[string]interface{}:
+		mapValues = dest
+	case *[]map[string]interface{}:
+		mapValues = *dest
+	}
+
+	for idx, row := range values.Values {
+		stmt.SQL.Reset()
+		stmt.Vars = nil
+		stmt.AddClause(clause.Values{Columns: values.Columns, Values: [][]interface{}{row}})
+		stmt.Build(stmt.BuildClauses...)
+
+		result, err := stmt.ConnPool.ExecContext(stmt.Context, stmt.SQL.String(), stmt.Vars...)
+		if err != nil && isDuplicatedKey(err) {
+			if onConflict.DoNothing {
+				continue
+			}
+			if len(onConflict.DoUpdates) > 0 {
+				if updated, ok, updateErr := updateOnConflict(db, onConflict, values.Columns, row, err); ok {
+					if db.AddError(updateErr) != nil {
+						return
+					}
+					affected, _ := updated.RowsAffected()
+					rowsAffected += affected
+					continue
+				}
+			}
+		}
+		if db.AddError(err) != nil {
+			return
+		}
+
+		affected, _ := result.RowsAffected()
+		rowsAffected += affected
+		if stmt.Result != nil {
+			stmt.Result.Result = result
+		}
+		if affected == 0 {
+			continue
+		}
+
+		if returning, ok := returningOf(stmt); ok {
+			if id, err := result.LastInsertId(); err == nil && id > 0 {
+				if mapValues == nil {
+					scanReturning(db, returning, id, rowValue(stmt, idx))
+				} else if mapValues[idx] != nil {
+					scanReturning(db, returning, id, reflect.ValueOf(mapValues[idx]))
+				}
+				if db.Error != nil {
+					return
+				}
+			}
+		}
+
+		if mapValues != nil {
+			if stmt.Schema != nil && pkField == nil {
+				continue
+			}
+			if mapValue := mapValues[idx]; mapValue != nil {
+				if _, ok := mapValue[pkFieldName]; !ok {
+					if key, ok := insertedKey(db, pkField, result); ok {
+						mapValue[pkFieldName] = key
+					}
+				}
+			}
+			continue
+		}
+
+		if pkField == nil {
+			continue
+		}
+
+		rv := rowValue(stmt, idx)
+		if reflect.Indirect(rv).Kind() != reflect.Struct {
+			continue
+		}
+		if _, isZero := pkField.ValueOf(stmt.Context, rv); isZero {
+			if key, ok := insertedKey(db, pkField, result); ok {
+				db.AddError(pkField.Set(stmt.Context, rv, key))
+			}
+		}
+	}
+
+	db.RowsAffected = rowsAffected
+	if stmt.Result != nil {
+		stmt.Result.RowsAffected = rowsAffected
+	}
+}
+
+// rowValue returns the value of the idx-th row being created.
+func rowValue(stmt *gorm.Statement, idx int) reflect.Value {
+	rv := stmt.ReflectValue
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		rv = rv.Index(idx)
+	}
+	return rv
+}
+
+// hasGeneratedKey reports whether the primary key is generated by its
+// DEFAULT, like $SYSTEM.Util.CreateGUID(), rather than being an identity
+func hasGeneratedKey(stmt *gorm.Statement) bool {
+	if stmt.Schema == nil {
+		return false
+	}
+	field := stmt.Schema.PrioritizedPrimaryField
+	return field != nil && field.HasDefaultValue && !field.AutoIncrement
+}
+
+// insertedKey returns the primary key of the row just inserted, which is
+// LAST_IDENTITY() for identities, and is read by RowID otherwise, as
+// LAST_IDENTITY() is the RowID of tables with no identity.
+func insertedKey(db *gorm.DB, pkField *schema.Field, result sql.Result) (interface{}, bool) {
+	insertID, err := result.LastInsertId()
+	if err != nil || insertID <= 0 {
+		return nil, false
+	}
+	if pkField == nil || pkField.AutoIncrement {
+		return insertID, true
+	}
+
+	var key interface{}
+	stmt := db.Statement
+	err = stmt.ConnPool.QueryRowContext(
+		stmt.Context,
+		"SELECT "+stmt.Quote(pkField.DBName)+" FROM "+stmt.Quote(clause.Table{Name: clause.CurrentTable})+" WHERE %ID = ?",
+		insertID,
+	).Scan(&key)
+	if db.AddError(err) != nil {
+		return nil, false
+	}
+	return key, true
+}
+
+// conflictColumns returns the columns identifying the conflicting row,
+// by default the primary key.
+func conflictColumns(stmt *gorm.Statement, onConflict clause.OnConflict) (columns []string) {
+	for _, column := range onConflict.Columns {
+		columns = append(columns, column.Name)
+	}
+
+	if len(columns) == 0 && stmt.Schema != nil {
+		if onConflict.OnConstraint != "" {
+			if index := stmt.Schema.LookIndex(onConflict.OnConstraint); index != nil {
+				for _, field := range index.Fields {
+					columns = append(columns, field.DBName)
+				}
+			} else if unique, ok := stmt.Schema.ParseUniqueConstraints()[onConflict.OnConstraint]; ok {
+				columns = append(columns, unique.Field.DBName)
+			}
+		} else {
+			for _, field := range stmt.Schema.PrimaryFields {
+				columns = append(columns, field.DBName)
+			}
+		}
+	}
+	return
+}
+
+// excludedRegexp matches the references to excluded columns in SQL
+var excludedRegexp = regexp.MustCompile(`(?i)\bexcluded\s*\.\s*"?(\w+)"?`)
+
+// excludedValue returns value with its references to excluded columns,
+// as clause.Column or in the SQL of an expression, replaced by the values
+// of the row being inserted.
+func excludedValue(value interface{}, valueOf func(name string) (interface{}, bool)) (interface{}, error) {
+	switch v := value.(type) {
+	case clause.Column:
+		if v.Table != "excluded" {
+			return value, nil
+		}
+		if value, ok := valueOf(v.Name); ok {
+			return value, nil
+		}
+		return nil, fmt.Errorf("iris: excluded.%s is not a column of the insert", v.Name)
+	case clause.Expr:
+		matches := excludedRegexp.FindAllStringSubmatchIndex(v.SQL, -1)
+		if len(matches) > 0 && strings.Count(v.SQL, "?") != len(v.Vars) {
+			return nil, fmt.Errorf("iris: cannot replace excluded columns of %s", v.SQL)
+		}
+
+		var (
+			expr      strings.Builder
+			vars      []interface{}
+			last, idx int
+		)
+		takeVars := func(sql string) {
+			n := strings.Count(sql, "?")
+			vars = append(vars, v.Vars[idx:idx+n]...)
+			idx += n
+		}
+		if len(matches) == 0 {
+			vars = append(vars, v.Vars...)
+		}
+		for _, match := range matches {
+			takeVars(v.SQL[last:match[0]])
+			value, ok := valueOf(v.SQL[match[2]:match[3]])
+			if !ok {
+				return nil, fmt.Errorf("iris: %s is not a column of the insert", v.SQL[match[0]:match[1]])
+			}
+			expr.WriteString(v.SQL[last:match[0]])
+			expr.WriteByte('?')
+			vars = append(vars, value)
+			last = match[1]
+		}
+		if len(matches) > 0 {
+			takeVars(v.SQL[last:])
+		}
+		expr.WriteString(v.SQL[last:])
+
+		for i, value := range vars {
+			var err error
+			if vars[i], err = excludedValue(value, valueOf); err != nil {
+				return nil, err
+			}
+		}
+		v.SQL, v.Vars = expr.String(), vars
+		return v, nil
+	}
+	return value, nil
+}
+
+// updateOnConflict updates the row the insert of row conflicted with,
+// references to excluded columns are replaced by the values of row.
+// It reports false when the conflicting row cannot be identified, and
+// returns insertErr when no row has the values of the conflict columns, as
+// the insert conflicted on another key then.
+func updateOnConflict(db *gorm.DB, onConflict clause.OnConflict, columns []clause.Column, row []interface{}, insertErr error) (sql.Result, bool, error) {
+	valueOf := func(name string) (interface{}, bool) {
+		for idx, column := range columns {
+			if column.Name == name {
+				return row[idx], true
+			}
+		}
+		return nil, false
+	}
+
+	conflict := clause.Where{}
+	for _, name := range conflictColumns(db.Statement, onConflict) {
+		value, ok := valueOf(name)
+		if !ok {
+			return nil, false, nil
+		}
+		conflict.Exprs = append(conflict.Exprs, clause.Eq{Column: clause.Column{Name: name}, Value: value})
+	}
+	if len(conflict.Exprs) == 0 {
+		return nil, false, nil
+	}
+	where := clause.Where{Exprs: append(append([]clause.Expression{}, conflict.Exprs...), onConflict.Where.Exprs...)}
+
+	set := make(clause.Set, len(onConflict.DoUpdates))
+	for idx, assignment := range onConflict.DoUpdates {
+		value, err := excludedValue(assignment.Value, valueOf)
+		if err != nil {
+			return nil, true, err
+		}
+		assignment.Value = value
+		set[idx] = assignment
+	}
+
+	stmt := &gorm.Statement{
+		DB:        db,
+		ConnPool:  db.Statement.ConnPool,
+		Context:   db.Statement.Context,
+		Table:     db.Statement.Table,
+		TableExpr: db.Statement.TableExpr,
+		Schema:    db.Statement.Schema,
+		Clauses:   map[string]clause.Clause{},
+	}
+	stmt.AddClause(clause.Update{})
+	stmt.AddClause(set)
+	stmt.AddClause(where)
+	stmt.Build("UPDATE", "SET", "WHERE")
+
+	result, err := stmt.ConnPool.ExecContext(stmt.Context, stmt.SQL.String(), stmt.Vars...)
+	if err != nil {
+		return nil, true, err
+	}
+	if affected, _ := result.RowsAffected(); affected > 0 {
+		return result, true, nil
+	}
+
+	// with no row updated, the row conflicted with is the one of the
+	// conflict columns only when the conditions of OnConflict left it out
+	if len(onConflict.Where.Exprs) == 0 {
+		return nil, true, insertErr
+	}
+	countStmt := &gorm.Statement{
+		DB:        db,
+		Context:   stmt.Context,
+		Table:     stmt.Table,
+		TableExpr: stmt.TableExpr,
+		Clauses:   map[string]clause.Clause{},
+	}
+	countStmt.AddClause(clause.Select{Expression: clause.Expr{SQL: "COUNT(*)"}})
+	countStmt.AddClause(clause.From{})
+	countStmt.AddClause(conflict)
+	countStmt.Build("SELECT", "FROM", "WHERE")
+
+	var count int64
+	if err := stmt.ConnPool.QueryRowContext(stmt.Context, countStmt.SQL.String(), countStmt.Vars...).Scan(&count); err != nil {
+		return nil, true, err
+	}
+	if count == 0 {
+		return nil, true, insertErr
+	}
+	return result, true, nil
+}
@@ -0,0 +1,111 @@
+package iris
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/caretdev/go-irisnative/src/connection"
+	"gorm.io/gorm"
+)
+
+// Error is an SQL error reported by IRIS, returned by Translate when
+// gorm.Config{TranslateError: true}. It wraps the matching GORM error, if
+// any, so both errors.Is(err, gorm.ErrDuplicatedKey) and
+// errors.As(err, &irisErr) work.
+type Error struct {
+	SQLCode int
+	Message string
+	Table   string
+	Column  string
+
+	translated error
+	err        error
+}
+
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	if e.translated != nil {
+		return []error{e.translated, e.err}
+	}
+	return []error{e.err}
+}
+
+// errorTranslations maps IRIS SQLCODEs to GORM errors
+var errorTranslations = map[int]error{
+	-29:  gorm.ErrInvalidField,            // Field not found in the applicable tables
+	-104: gorm.ErrCheckConstraintViolated, // Field validation failed in INSERT
+	-105: gorm.ErrCheckConstraintViolated, // Field validation failed in UPDATE
+	-108: gorm.ErrCheckConstraintViolated, // Required field missing
+	-110: ErrLockTimeout,                  // Locking conflict in filing
+	-114: ErrLockTimeout,                  // One or more matching rows is locked by another user
+	-119: gorm.ErrDuplicatedKey,           // UNIQUE or PRIMARY KEY constraint failed uniqueness check upon INSERT
+	-120: gorm.ErrDuplicatedKey,           // UNIQUE or PRIMARY KEY constraint failed uniqueness check upon UPDATE
+	-121: gorm.ErrForeignKeyViolated,      // FOREIGN KEY constraint failed referential check upon INSERT
+	-122: gorm.ErrForeignKeyViolated,      // FOREIGN KEY constraint failed referential check upon UPDATE
+	-123: gorm.ErrForeignKeyViolated,      // FOREIGN KEY constraint failed referential check upon UPDATE of referenced row
+	-124: gorm.ErrForeignKeyViolated,      // FOREIGN KEY constraint failed referential check upon DELETE
+}
+
+var (
+	errorTableRegexp  = regexp.MustCompile(`[Tt]able '([^']+)'`)
+	errorColumnRegexp = regexp.MustCompile(`Field(?:\(s\))? '?([^'=;,\s]+)`)
+)
+
+// Translate implements gorm.ErrorTranslator.
+func (dialector Dialector) Translate(err error) error {
+	if irisErr := asError(err); irisErr != nil {
+		return irisErr
+	}
+	return err
+}
+
+// asError converts an error returned by the driver to *Error.
+func asError(err error) *Error {
+	var irisErr *Error
+	if errors.As(err, &irisErr) {
+		return irisErr
+	}
+
+	var sqlErr *connection.SQLError
+	if !errors.As(err, &sqlErr) {
+		return nil
+	}
+
+	irisErr = &Error{
+		SQLCode:    int(sqlErr.SQLCode),
+		Message:    sqlErr.Message,
+		translated: errorTranslations[int(sqlErr.SQLCode)],
+		err:        err,
+	}
+	if matches := errorTableRegexp.FindStringSubmatch(sqlErr.Message); len(matches) > 1 {
+		irisErr.Table = matches[1]
+	}
+	if matches := errorColumnRegexp.FindStringSubmatch(sqlErr.Message); len(matches) > 1 {
+		irisErr.Column = matches[1]
+		// Field 'Schema.Table.Column'
+		if idx := strings.LastIndexByte(irisErr.Column, '.'); idx > 0 {
+			if irisErr.Table == "" {
+				irisErr.Table = irisErr.Column[:idx]
+			}
+			irisErr.Column = irisErr.Column[idx+1:]
+		}
+	}
+	return irisErr
+}
+
+// sqlCodeOf returns SQLCODE of err, or 0 when err is not an IRIS error.
+func sqlCodeOf(err error) int {
+	if irisErr := asError(err); irisErr != nil {
+		return irisErr.SQLCode
+	}
+	return 0
+}
+
+func isDuplicatedKey(err error) bool {
+	code := sqlCodeOf(err)
+	return code == -119 || code == -120
+}
@@ -0,0 +1,155 @@
+package iris
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+)
+
+// sqlStatement is one of the statements of a multi-statement SQL, with the
+// number of its ? placeholders
+type sqlStatement struct {
+	SQL  string
+	Vars int
+}
+
+// Exec replaces gorm:raw callback.
+// SQL made of several statements is not left to the driver, each statement
+// is run on its own, in a transaction on the same connection, and an error
+// tells which statement failed.
+func Exec(db *gorm.DB) {
+	if db.Error != nil || db.DryRun {
+		return
+	}
+
+	statements := splitStatements(db.Statement.SQL.String())
+	if len(statements) <= 1 {
+		callbacks.RawExec(db)
+		return
+	}
+	execStatements(db, statements, db.Statement.Vars)
+}
+
+// execStatements runs statements one by one in the transaction of db, or in
+// a new one, with vars taken in turn by their placeholders.
+func execStatements(db *gorm.DB, statements []sqlStatement, vars []interface{}) {
+	placeholders := 0
+	for _, statement := range statements {
+		placeholders += statement.Vars
+	}
+	if placeholders != len(vars) {
+		db.AddError(fmt.Errorf("iris: %d arguments given for %d placeholders", len(vars), placeholders))
+		return
+	}
+
+	stmt := db.Statement
+	inTransaction(db, func() {
+		var rowsAffected int64
+		for idx, statement := range statements {
+			result, err := stmt.ConnPool.ExecContext(stmt.Context, statement.SQL, vars[:statement.Vars]...)
+			if err != nil {
+				db.AddError(fmt.Errorf("iris: statement %d of %d failed, %s: %w", idx+1, len(statements), statement.SQL, err))
+				return
+			}
+			vars = vars[statement.Vars:]
+
+			affected, _ := result.RowsAffected()
+			rowsAffected += affected
+			if stmt.Result != nil {
+				stmt.Result.Result = result
+			}
+		}
+
+		db.RowsAffected = rowsAffected
+		if stmt.Result != nil {
+			stmt.Result.RowsAffected = rowsAffected
+		}
+	})
+}
+
+// blockEnds are the words after END which do not end a BEGIN or CASE block
+var blockEnds = map[string]bool{"IF": true, "LOOP": true, "WHILE": true, "REPEAT": true, "FOR": true}
+
+// splitStatements splits sql on the semicolons ending its statements, the
+// ones out of literals, comments, {} bodies and BEGIN ... END blocks.
+func splitStatements(sql string) (statements []sqlStatement) {
+	var (
+		start, vars    int
+		braces, blocks int
+	)
+	appendStatement := func(end int) {
+		if statement := strings.TrimSpace(sql[start:end]); statement != "" {
+			statements = append(statements, sqlStatement{SQL: statement, Vars: vars})
+		}
+		start, vars = end+1, 0
+	}
+
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '\'' || c == '"':
+			// a quote in a literal or identifier is doubled
+			for i++; i < len(sql); i++ {
+				if sql[i] == c {
+					if i+1 < len(sql) && sql[i+1] == c {
+						i++
+						continue
+					}
+					break
+				}
+			}
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			if end := strings.IndexByte(sql[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(sql)
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			if end := strings.Index(sql[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(sql)
+			}
+		case c == '{':
+			braces++
+		case c == '}':
+			if braces > 0 {
+				braces--
+			}
+		case c == '?':
+			vars++
+		case c == ';':
+			if braces == 0 && blocks == 0 {
+				appendStatement(i)
+			}
+		case isWordByte(c):
+			word := sqlWord(sql[i:])
+			switch strings.ToUpper(word) {
+			case "BEGIN", "CASE":
+				blocks++
+			case "END":
+				if next := sqlWord(strings.TrimLeft(sql[i+len(word):], " \t\r\n")); blocks > 0 && !blockEnds[strings.ToUpper(next)] {
+					blocks--
+				}
+			}
+			i += len(word) - 1
+		}
+	}
+	appendStatement(len(sql))
+	return
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '%' || c == '$' || c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+// sqlWord returns the word s starts with
+func sqlWord(s string) string {
+	for i := 0; i < len(s); i++ {
+		if !isWordByte(s[i]) {
+			return s[:i]
+		}
+	}
+	return s
+}
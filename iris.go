@@ -1,8 +1,12 @@
 package iris
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 
 	_ "github.com/caretdev/go-irisnative"
 	"gorm.io/gorm"
@@ -15,6 +19,12 @@ import (
 
 const (
 	DefaultDriverName = "iris"
+	// DefaultSchemaSetting overrides Config.DefaultSchema for a session,
+	// db.Set(iris.DefaultSchemaSetting, "MySchema")
+	DefaultSchemaSetting = "iris:default_schema"
+	// ColumnarStorageSetting creates the tables of a session with columnar
+	// storage, db.Set(iris.ColumnarStorageSetting, true).AutoMigrate(&Stats{})
+	ColumnarStorageSetting = "iris:columnar_storage"
 )
 
 var (
@@ -22,10 +32,32 @@ var (
 )
 
 type Config struct {
-	DriverName    string
+	DriverName string
+	// ServerVersion is $ZVERSION of the server, queried on Initialize when
+	// empty, see Dialector.Version and Dialector.Supports
 	ServerVersion string
 	DSN           string
 	Conn          gorm.ConnPool
+	// DefaultSchema is the schema of unqualified table names, in migrator
+	// catalog queries, DDL and DML, the connection's default schema when
+	// empty
+	DefaultSchema string
+	// LockTimeout is seconds to wait for the rows of clause.Locking queries,
+	// IRIS default of 10 seconds when 0
+	LockTimeout int
+	// DefaultStringSize is the size of strings with no size, 65535 when 0,
+	// strings are LONGVARCHAR streams only with type:text
+	DefaultStringSize int
+	// POSIXTime stores time.Time as POSIXTIME, microseconds since epoch,
+	// instead of TIMESTAMP
+	POSIXTime bool
+	// InsertOrUpdate makes every INSERT an INSERT OR UPDATE, as it was
+	// before clause.OnConflict support, so duplicate keys never fail.
+	InsertOrUpdate bool
+
+	// serverSchema is the default schema of the connection, where IRIS
+	// looks for unqualified table names
+	serverSchema string
 }
 
 type Dialector struct {
@@ -102,10 +134,16 @@ func (dialector Dialector) DataTypeOf(field *schema.Field) string {
 	case schema.Bool:
 		return "BIT"
 	case schema.String:
-		if field.Size > 0 {
-			return fmt.Sprintf("varchar(%d)", field.Size)
+		size := field.Size
+		if size == 0 {
+			size = dialector.DefaultStringSize
+		}
+		if size == 0 {
+			size = 65535
+		} else if size > maxStringLength {
+			return "LONGVARCHAR"
 		}
-		return "varchar(65535)"
+		return fmt.Sprintf("varchar(%d)", size)
 	case schema.Int, schema.Uint:
 		size := field.Size
 		if field.DataType == schema.Uint {
@@ -127,15 +165,35 @@ func (dialector Dialector) DataTypeOf(field *schema.Field) string {
 	case schema.Float:
 		if field.Precision > 0 {
 			if field.Scale > 0 {
-				return fmt.Sprintf("numeric(%d, %d)", field.Precision, field.Scale)
+				return fmt.Sprintf("numeric(%d,%d)", field.Precision, field.Scale)
 			}
 			return fmt.Sprintf("numeric(%d)", field.Precision)
 		}
 		return "decimal"
 	case schema.Time:
+		if strings.EqualFold(field.TagSettings["TYPE"], "time") {
+			if field.Precision > 0 {
+				return fmt.Sprintf("TIME(%d)", field.Precision)
+			}
+			return "TIME"
+		}
+		if dialector.POSIXTime {
+			return "POSIXTIME"
+		}
+		if field.Precision > 0 {
+			return fmt.Sprintf("timestamp(%d)", field.Precision)
+		}
 		return "timestamp"
 	case schema.Bytes:
-		return "BINARY(65535)"
+		size := field.Size
+		if size == 0 && isKey(field) {
+			// streams can not be keys, indexed or compared
+			size = 65535
+		}
+		if size == 0 || size > maxStringLength {
+			return "LONGVARBINARY"
+		}
+		return fmt.Sprintf("varbinary(%d)", size)
 	default:
 		return dialector.getSchemaCustomType(field)
 		// panic("unimplemented: DataTypeOf for " + field.DataType)
@@ -145,6 +203,12 @@ func (dialector Dialector) DataTypeOf(field *schema.Field) string {
 
 func (dialector Dialector) getSchemaCustomType(field *schema.Field) string {
 	sqlType := string(field.DataType)
+	if streamType, ok := streamTypes[strings.ToLower(sqlType)]; ok {
+		return streamType
+	}
+	if strings.EqualFold(sqlType, "uuid") {
+		return "UNIQUEIDENTIFIER"
+	}
 
 	// if field.AutoIncrement && !strings.Contains(strings.ToLower(sqlType), " auto_increment") {
 	// 	sqlType += " AUTO_INCREMENT"
@@ -154,8 +218,64 @@ func (dialector Dialector) getSchemaCustomType(field *schema.Field) string {
 }
 
 func (dialector Dialector) DefaultValueOf(field *schema.Field) Expression {
-	return nil
-	// return Expr{SQL: "DEFAULT"}
+	if defaultValue, ok := dialector.defaultValue(field); ok {
+		return Expr{SQL: defaultValue}
+	}
+	return Expr{SQL: "NULL"}
+}
+
+// defaultKeywords are DEFAULT values that IRIS evaluates, written unquoted
+// even for string fields
+var defaultKeywords = map[string]bool{
+	"CURRENT_DATE":      true,
+	"CURRENT_TIME":      true,
+	"CURRENT_TIMESTAMP": true,
+	"CURRENT_USER":      true,
+	"SESSION_USER":      true,
+	"SYSTEM_USER":       true,
+	"SYSDATE":           true,
+	"USER":              true,
+}
+
+// defaultValue returns DEFAULT of the field as it is written in DDL,
+// literals are quoted, booleans are 1 or 0, and functions, keywords and
+// $ variables, like $HOROLOG, are kept as they are
+func (dialector Dialector) defaultValue(field *schema.Field) (string, bool) {
+	if !field.HasDefaultValue || (field.DefaultValueInterface == nil && field.DefaultValue == "") ||
+		field.DefaultValue == "(-)" || strings.EqualFold(field.DefaultValue, "NULL") {
+		return "", false
+	}
+	if tag := strings.TrimSpace(field.TagSettings["DEFAULT"]); strings.HasPrefix(tag, "$") || defaultKeywords[strings.ToUpper(tag)] {
+		return tag, true
+	}
+	switch value := field.DefaultValueInterface.(type) {
+	case nil:
+	case bool:
+		if value {
+			return "1", true
+		}
+		return "0", true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(value), true
+	case float32:
+		return strconv.FormatFloat(float64(value), 'g', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64), true
+	default:
+		return dialector.Explain("?", value), true
+	}
+	if function, ok := defaultFunctions[strings.ToLower(field.DefaultValue)]; ok {
+		return function, true
+	}
+	return field.DefaultValue, true
+}
+
+// defaultFunctions maps DEFAULT functions of other databases to IRIS
+var defaultFunctions = map[string]string{
+	"gen_random_uuid()":  "$SYSTEM.Util.CreateGUID()",
+	"uuid_generate_v4()": "$SYSTEM.Util.CreateGUID()",
+	"uuid()":             "$SYSTEM.Util.CreateGUID()",
+	"newid()":            "$SYSTEM.Util.CreateGUID()",
 }
 
 func (dialector Dialector) Apply(config *gorm.Config) error {
@@ -174,6 +294,18 @@ func (dialector Dialector) Initialize(db *gorm.DB) (err error) {
 		LastInsertIDReversed: true,
 	}
 	callbacks.RegisterDefaultCallbacks(db, callbackConfig)
+	db.Callback().Create().Replace("gorm:create", Create(callbackConfig))
+	db.Callback().Update().Replace("gorm:update", UpdateWithReturning(callbackConfig))
+	db.Callback().Delete().Replace("gorm:delete", DeleteWithReturning(callbackConfig))
+	db.Callback().Raw().Replace("gorm:raw", Exec)
+	db.Callback().Raw().Before("gorm:raw").Register("iris:create_table_if_not_exists", createTableIfNotExists)
+	db.Callback().Create().Before("*").Register("iris:qualify_table", QualifyTable)
+	db.Callback().Query().Before("*").Register("iris:qualify_table", QualifyTable)
+	db.Callback().Update().Before("*").Register("iris:qualify_table", QualifyTable)
+	db.Callback().Delete().Before("*").Register("iris:qualify_table", QualifyTable)
+	db.Callback().Row().Before("*").Register("iris:qualify_table", QualifyTable)
+	db.Callback().Query().Before("gorm:query").Register("iris:lock", Lock)
+	db.Callback().Query().After("gorm:query").Register("iris:read_streams", ReadStreams)
 
 	for k, v := range dialector.ClauseBuilders() {
 		if _, ok := db.ClauseBuilders[k]; !ok {
@@ -181,9 +313,28 @@ func (dialector Dialector) Initialize(db *gorm.DB) (err error) {
 		}
 	}
 
-	db.ConnPool, err = sql.Open(dialector.DriverName, dialector.Config.DSN)
-	if err != nil {
-		panic(err)
+	if dialector.Conn != nil {
+		db.ConnPool = dialector.Conn
+	} else {
+		db.ConnPool, err = OpenDB(dialector.DriverName, dialector.Config.DSN)
+		if err != nil {
+			return err
+		}
+	}
+
+	if dialector.ServerVersion == "" {
+		err = db.ConnPool.QueryRowContext(context.Background(), "SELECT $ZVERSION").Scan(&dialector.ServerVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Schema.Default() is missing on old releases, where SQLUser is used
+	if db.ConnPool.QueryRowContext(context.Background(), "SELECT $SYSTEM.SQL.Schema.Default()").Scan(&dialector.serverSchema) != nil {
+		dialector.serverSchema = ""
+	}
+	if dialector.DefaultSchema == "" {
+		dialector.DefaultSchema = dialector.serverSchema
 	}
 	// db.Set("gorm:table_options", " WITH %CLASSPARAMETER ALLOWIDENTITYINSERT = 1")
 	return
@@ -193,8 +344,9 @@ type IRISDB struct {
 	*gorm.DB
 }
 
+// Deprecated: gorm.DB.CreateInBatches works with IRIS as is.
 func (irisdb *IRISDB) CreateInBatches(value interface{}, batchSize int) (tx *gorm.DB) {
-	return irisdb.DB.CreateInBatches(value, 1)
+	return irisdb.DB.CreateInBatches(value, batchSize)
 }
 
 func (dialector Dialector) Migrator(db *gorm.DB) gorm.Migrator {
@@ -210,6 +362,12 @@ func (dialector Dialector) Migrator(db *gorm.DB) gorm.Migrator {
 	}
 }
 
+// Namespaces lists the namespaces visible to the connection of db.
+func (dialector Dialector) Namespaces(db *gorm.DB) (namespaces []string, err error) {
+	err = db.Raw("SELECT Nsp FROM %SYS.Namespace_List() ORDER BY Nsp").Scan(&namespaces).Error
+	return
+}
+
 func (dialector Dialector) Explain(sql string, avars ...interface{}) string {
 	var (
 		convertParams func(interface{}, int)
@@ -235,48 +393,25 @@ func (dialector Dialector) Explain(sql string, avars ...interface{}) string {
 	return logger.ExplainSQL(sql, nil, `'`, vars...)
 }
 
-type DopeWriter struct {
-}
-
-func (stmt DopeWriter) WriteString(string) (int, error) {
-	return 0, nil
-}
-
-func (stmt DopeWriter) WriteByte(c byte) error {
-	return nil
-}
-
 func (dialector Dialector) ClauseBuilders() map[string]ClauseBuilder {
 	clauseBuilders := map[string]ClauseBuilder{
 		"WHERE": func(c Clause, builder Builder) {
 			if where, ok := c.Expression.(Where); ok && len(where.Exprs) > 0 {
-				var exprs = where.Exprs
-				for idx, expr := range where.Exprs {
-					// Replace multicolumn IN with AND
-					if in, ok := expr.(IN); ok {
-						if columns, ok := in.Column.([]Column); ok {
-							var values = in.Values[0].([]interface{})
-							if len(columns) == len(values) {
-								var newExprs = make([]Expression, len(columns))
-								for i := 0; i < len(columns); i++ {
-									newExprs[i] = Eq{
-										Column: columns[i],
-										Value: values[i],
-									}
-								}
-								exprs[idx] = AndConditions{
-									Exprs: newExprs,
-								}
-							}
-						}
-					}
-				}
+				c.Expression = Where{Exprs: tupleINs(where.Exprs)}
 			}
 			c.Build(builder)
 		},
 		"INSERT": func(c Clause, builder Builder) {
 			if insert, ok := c.Expression.(Insert); ok {
-				builder.WriteString("INSERT OR UPDATE ")
+				upsert := dialector.InsertOrUpdate
+				if stmt, ok := builder.(*gorm.Statement); ok {
+					upsert = dialector.insertsOrUpdates(stmt)
+				}
+				if upsert {
+					builder.WriteString("INSERT OR UPDATE ")
+				} else {
+					builder.WriteString("INSERT INTO ")
+				}
 				if insert.Table.Name == "" {
 					builder.WriteQuoted(currentTable)
 				} else {
@@ -284,38 +419,6 @@ func (dialector Dialector) ClauseBuilders() map[string]ClauseBuilder {
 				}
 			}
 		},
-		"VALUES": func(c Clause, builder Builder) {
-			var dopeWriter = DopeWriter{}
-			if values, ok := c.Expression.(Values); ok {
-				// if len(values.Values) > 1 {
-				// 	panic(fmt.Sprintf("Create in batches not supported by IRIS: %d;", len(values.Values)))
-				// }
-				if len(values.Columns) == 0 {
-					builder.WriteString("DEFAULT VALUES")
-					return
-				}
-				builder.WriteByte('(')
-				for idx, column := range values.Columns {
-					if idx > 0 {
-						builder.WriteByte(',')
-					}
-					builder.WriteQuoted(column)
-				}
-				builder.WriteByte(')')
-				builder.WriteString(" VALUES ")
-				for idx, value := range values.Values {
-					if idx > 0 {
-						builder.AddVar(dopeWriter, value...)
-					} else {
-						builder.WriteByte('(')
-						builder.AddVar(builder, value...)
-						builder.WriteByte(')')
-					}
-				}
-				return
-			}
-			c.Build(builder)
-		},
 		"GROUP BY": func(c Clause, builder Builder) {
 			if groupBy, ok := c.Expression.(GroupBy); ok {
 				builder.WriteString("GROUP BY ")
@@ -336,83 +439,154 @@ func (dialector Dialector) ClauseBuilders() map[string]ClauseBuilder {
 			}
 			c.Build(builder)
 		},
-		"SELECT": func(c Clause, builder Builder) {
-			if s, ok := c.Expression.(Select); ok {
-				builder.WriteString("SELECT ")
-				if len(s.Columns) > 0 {
-					if s.Distinct {
-						builder.WriteString("DISTINCT ")
-					}
-
-					for idx, column := range s.Columns {
-						if idx > 0 {
-							builder.WriteByte(',')
-						}
-						if s.Distinct {
-							builder.WriteString("%EXACT(")
-							builder.WriteQuoted(column)
-							builder.WriteString(") AS ")
-						}
-						builder.WriteQuoted(column)
-					}
-				} else {
-					builder.WriteByte('*')
-				}
-
-				return
-			}
-			c.Build(builder)
+		"SELECT": dialector.buildSelect,
+		"VALUES": dialector.buildValues,
+		"SET":    dialector.buildSet,
+		"LIMIT":  dialector.buildLimit,
+		"FOR": func(c Clause, builder Builder) {
+			// IRIS has no FOR UPDATE, rows are locked by Lock callback
 		},
 		"ON CONFLICT": func(c Clause, builder Builder) {
-			if onConflict, ok := c.Expression.(OnConflict); ok {
-				// Some tricks to make it working with IRIS
-				if onConflict.DoNothing {
-					builder.WriteString(";\n-- ON CONFLICT DO NOTHING")
-					return
-				} else {
-					builder.WriteString(";\n-- ON CONFLICT UPDATE")
-					return
-				}
-			}
+			// IRIS has no ON CONFLICT, full-row upserts are done by INSERT OR UPDATE,
+			// and the rest is emulated by Create callback
 		},
 		"RETURNING": func(c Clause, builder Builder) {
-			if returning, ok := c.Expression.(Returning); ok {
-				builder.WriteString(";\nSELECT ")
-				var table string
-				if len(returning.Columns) > 0 {
-					for idx, column := range returning.Columns {
-						table = column.Table
-						if idx > 0 {
-							builder.WriteByte(',')
-						}
-
-						builder.WriteQuoted(column)
+			// IRIS has no RETURNING, returned rows are read by Create, Update
+			// and Delete callbacks
+		},
+	}
+
+	return clauseBuilders
+}
+
+// tupleINs returns exprs with their multi-column IN rewritten by tupleIN,
+// in AND, OR and NOT conditions too.
+func tupleINs(exprs []Expression) []Expression {
+	rewritten := make([]Expression, len(exprs))
+	for idx, expr := range exprs {
+		rewritten[idx] = expr
+		switch e := expr.(type) {
+		case IN:
+			if tuples, ok := tupleIN(e); ok {
+				rewritten[idx] = tuples
+			}
+		case Expr:
+			if in, negated, ok := tupleExprIN(e); ok {
+				if tuples, ok := tupleIN(in); ok {
+					rewritten[idx] = tuples
+					if negated {
+						rewritten[idx] = NotConditions{Exprs: []Expression{tuples}}
 					}
-				} else {
-					builder.WriteByte('*')
 				}
-				builder.WriteString(" FROM ")
-				if table == "" {
-					builder.WriteQuoted(currentTable)
-				} else {
-					builder.WriteQuoted(table)
-				}
-				builder.WriteString(" WHERE %ID = LAST_IDENTITY()")
-				return
 			}
-			// c.Build(builder)
-		},
+		case AndConditions:
+			rewritten[idx] = AndConditions{Exprs: tupleINs(e.Exprs)}
+		case OrConditions:
+			rewritten[idx] = OrConditions{Exprs: tupleINs(e.Exprs)}
+		case NotConditions:
+			// NOT of the rewritten conditions is NOT ((a = ? AND b = ?) OR ...)
+			rewritten[idx] = NotConditions{Exprs: tupleINs(e.Exprs)}
+		}
 	}
+	return rewritten
+}
 
-	return clauseBuilders
+// tupleIN rewrites multi-column IN, which IRIS does not have, as
+//
+//	(a = ? AND b = ?) OR (a = ? AND b = ?)
+//
+// and an empty one as a false condition.
+func tupleIN(in IN) (Expression, bool) {
+	columns, ok := in.Column.([]Column)
+	if !ok {
+		return nil, false
+	}
+	if len(in.Values) == 0 {
+		// in parentheses, so NOT of it is true
+		return Expr{SQL: "(1 = 0)"}, true
+	}
+
+	tuples := make([]Expression, len(in.Values))
+	for idx, value := range in.Values {
+		values, ok := value.([]interface{})
+		if !ok || len(values) != len(columns) {
+			return nil, false
+		}
+		eqs := make([]Expression, len(columns))
+		for i, column := range columns {
+			eqs[i] = Eq{Column: column, Value: values[i]}
+		}
+		tuples[idx] = AndConditions{Exprs: eqs}
+	}
+	if len(tuples) == 1 {
+		return tuples[0], true
+	}
+	return OrConditions{Exprs: tuples}, true
 }
 
-func (dialector Dialector) SavePoint(tx *gorm.DB, name string) error {
-	tx.Exec("SAVEPOINT " + name)
+var tupleINRegexp = regexp.MustCompile(`(?is)^\s*\(([^()]+)\)\s+(NOT\s+)?IN\s+\?\s*$`)
+
+// tupleExprIN parses conditions like db.Where("(a, b) IN ?", pairs), or
+// "(a, b) NOT IN ?", which is reported as negated.
+func tupleExprIN(expr Expr) (in IN, negated bool, ok bool) {
+	matches := tupleINRegexp.FindStringSubmatch(expr.SQL)
+	if matches == nil || len(expr.Vars) != 1 {
+		return in, false, false
+	}
+	negated = matches[2] != ""
+
+	var columns []Column
+	for _, name := range strings.Split(matches[1], ",") {
+		columns = append(columns, Column{Name: strings.TrimSpace(name), Raw: true})
+	}
+
+	rv := reflect.ValueOf(expr.Vars[0])
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return in, false, false
+	}
+	in = IN{Column: columns, Values: make([]interface{}, rv.Len())}
+	for i := 0; i < rv.Len(); i++ {
+		tuple := reflect.Indirect(rv.Index(i))
+		if tuple.Kind() == reflect.Interface {
+			tuple = tuple.Elem()
+		}
+		if tuple.Kind() != reflect.Slice && tuple.Kind() != reflect.Array {
+			return in, false, false
+		}
+		values := make([]interface{}, tuple.Len())
+		for j := range values {
+			values[j] = tuple.Index(j).Interface()
+		}
+		in.Values[i] = values
+	}
+	return in, negated, true
+}
+
+var savePointNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func checkSavePointName(name string) error {
+	if !savePointNameRegexp.MatchString(name) {
+		return fmt.Errorf("iris: invalid savepoint name %q", name)
+	}
 	return nil
 }
 
+func (dialector Dialector) SavePoint(tx *gorm.DB, name string) error {
+	if err := checkSavePointName(name); err != nil {
+		return err
+	}
+	return tx.Exec("SAVEPOINT " + name).Error
+}
+
 func (dialector Dialector) RollbackTo(tx *gorm.DB, name string) error {
-	tx.Exec("ROLLBACK TO SAVEPOINT " + name)
-	return nil
+	if err := checkSavePointName(name); err != nil {
+		return err
+	}
+	return tx.Exec("ROLLBACK TO SAVEPOINT " + name).Error
+}
+
+// ReleaseSavePoint only checks the name, IRIS has no RELEASE SAVEPOINT,
+// savepoints are released by COMMIT or ROLLBACK of the transaction.
+func (dialector Dialector) ReleaseSavePoint(tx *gorm.DB, name string) error {
+	return checkSavePointName(name)
 }
@@ -0,0 +1,228 @@
+package iris
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// JSON is a JSON document stored in a LONGVARCHAR column
+type JSON json.RawMessage
+
+// GormDataType implements schema.GormDataTypeInterface.
+func (JSON) GormDataType() string {
+	return "json"
+}
+
+// Value implements driver.Valuer.
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+// Scan implements sql.Scanner.
+func (j *JSON) Scan(value interface{}) error {
+	switch value := value.(type) {
+	case nil:
+		*j = nil
+	case string:
+		*j = JSON(value)
+	case []byte:
+		*j = append(JSON(nil), value...)
+	default:
+		return fmt.Errorf("iris: cannot scan %T into JSON", value)
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	if j == nil {
+		return errors.New("iris: UnmarshalJSON on nil pointer")
+	}
+	*j = append((*j)[0:0], data...)
+	return nil
+}
+
+func (j JSON) String() string {
+	return string(j)
+}
+
+// writeString writes a string literal
+func writeString(builder clause.Builder, s string) {
+	builder.WriteByte('\'')
+	builder.WriteString(strings.ReplaceAll(s, "'", "''"))
+	builder.WriteByte('\'')
+}
+
+// jsonPathEscaper escapes a key in a quoted member of a JSON path
+var jsonPathEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// jsonPath returns the path of nested keys, $."a"."b", keys are quoted so
+// they may hold dots, spaces or quotes
+func jsonPath(keys []string) string {
+	path := "$"
+	for _, key := range keys {
+		path += `."` + jsonPathEscaper.Replace(key) + `"`
+	}
+	return path
+}
+
+// JSONQueryExpression is a condition on keys of a JSON column, evaluated
+// with JSON_TABLE, as IRIS has no JSON_VALUE
+type JSONQueryExpression struct {
+	column      string
+	keys        []string
+	hasKey      bool
+	equals      bool
+	equalsValue interface{}
+}
+
+// JSONQuery starts a condition on the JSON column.
+//
+//	db.Where(iris.JSONQuery("attrs").HasKey("color"))
+//	db.Where(iris.JSONQuery("attrs").Equals("red", "color"))
+func JSONQuery(column string) *JSONQueryExpression {
+	return &JSONQueryExpression{column: column}
+}
+
+// HasKey matches documents with the nested keys, with a value other than null.
+func (q *JSONQueryExpression) HasKey(keys ...string) *JSONQueryExpression {
+	q.keys = keys
+	q.hasKey = true
+	return q
+}
+
+// Equals matches documents with value at the nested keys.
+func (q *JSONQueryExpression) Equals(value interface{}, keys ...string) *JSONQueryExpression {
+	q.keys = keys
+	q.equals = true
+	q.equalsValue = value
+	return q
+}
+
+// Build implements clause.Expression.
+func (q *JSONQueryExpression) Build(builder clause.Builder) {
+	if !q.hasKey && !q.equals {
+		return
+	}
+
+	builder.WriteString("EXISTS (SELECT 1 FROM JSON_TABLE(")
+	builder.WriteQuoted(clause.Column{Table: clause.CurrentTable, Name: q.column})
+	builder.WriteString(",'$' COLUMNS (\"value\" VARCHAR(")
+	builder.WriteString(strconv.Itoa(maxStringLength))
+	builder.WriteString(") PATH ")
+	writeString(builder, jsonPath(q.keys))
+	builder.WriteString(")) WHERE \"value\" ")
+
+	if q.hasKey {
+		builder.WriteString("IS NOT NULL)")
+		return
+	}
+
+	value := q.equalsValue
+	if b, ok := value.(bool); ok {
+		// JSON_TABLE returns booleans as they are written in JSON
+		value = strconv.FormatBool(b)
+	}
+	builder.WriteString("= ")
+	builder.AddVar(builder, value)
+	builder.WriteByte(')')
+}
+
+// JSONObject builds JSON_OBJECT of keys and values, values are columns,
+// expressions or bound values.
+//
+//	iris.JSONObject("name", clause.Column{Name: "name"}, "age", 18)
+func JSONObject(keysAndValues ...interface{}) clause.Expression {
+	return jsonObject(keysAndValues)
+}
+
+type jsonObject []interface{}
+
+func (o jsonObject) Build(builder clause.Builder) {
+	builder.WriteString("JSON_OBJECT(")
+	for i := 0; i+1 < len(o); i += 2 {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		writeString(builder, fmt.Sprint(o[i]))
+		builder.WriteByte(':')
+		builder.AddVar(builder, o[i+1])
+	}
+	builder.WriteByte(')')
+}
+
+// JSONArray builds JSON_ARRAY of values, which are columns, expressions or
+// bound values.
+func JSONArray(values ...interface{}) clause.Expression {
+	return jsonArray(values)
+}
+
+type jsonArray []interface{}
+
+func (a jsonArray) Build(builder clause.Builder) {
+	builder.WriteString("JSON_ARRAY(")
+	for i, value := range a {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.AddVar(builder, value)
+	}
+	builder.WriteByte(')')
+}
+
+// JSONTableColumn is a column of JSON_TABLE
+type JSONTableColumn struct {
+	Name string
+	Type string
+	Path string
+}
+
+// JSONTable builds JSON_TABLE of a JSON value, a column, an expression or
+// a bound string, to be used as a table.
+//
+//	db.Table("?", iris.JSONTable(`[{"a":1}]`, "$", iris.JSONTableColumn{Name: "a", Type: "INTEGER", Path: "$.a"}))
+func JSONTable(value interface{}, path string, columns ...JSONTableColumn) clause.Expression {
+	return jsonTable{value: value, path: path, columns: columns}
+}
+
+type jsonTable struct {
+	value   interface{}
+	path    string
+	columns []JSONTableColumn
+}
+
+func (t jsonTable) Build(builder clause.Builder) {
+	builder.WriteString("JSON_TABLE(")
+	builder.AddVar(builder, t.value)
+	builder.WriteByte(',')
+	writeString(builder, t.path)
+	builder.WriteString(" COLUMNS (")
+	for i, column := range t.columns {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteQuoted(column.Name)
+		builder.WriteByte(' ')
+		builder.WriteString(column.Type)
+		builder.WriteString(" PATH ")
+		writeString(builder, column.Path)
+	}
+	builder.WriteString("))")
+}
@@ -0,0 +1,124 @@
+package iris
+
+import (
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// topLimit returns LIMIT clause of the statement being built, when it has
+// to be done with TOP because the server has no LIMIT/OFFSET.
+//
+//	LIMIT n           -> SELECT TOP n ...
+//	LIMIT n OFFSET m  -> SELECT * FROM (SELECT TOP ALL ...) WHERE %VID > m AND %VID <= m+n
+func (dialector Dialector) topLimit(builder clause.Builder) (limit clause.Limit, ok bool) {
+	if dialector.Supports(FeatureLimitOffset) {
+		return limit, false
+	}
+
+	stmt, ok := builder.(*gorm.Statement)
+	if !ok {
+		return limit, false
+	}
+	c, ok := stmt.Clauses["LIMIT"]
+	if !ok {
+		return limit, false
+	}
+	if limit, ok = c.Expression.(clause.Limit); !ok {
+		return limit, false
+	}
+	return limit, (limit.Limit != nil && *limit.Limit >= 0) || limit.Offset > 0
+}
+
+// buildSelect writes SELECT clause, with TOP when LIMIT is emulated and
+// columns converted to be read
+func (dialector Dialector) buildSelect(c clause.Clause, builder clause.Builder) {
+	limit, top := dialector.topLimit(builder)
+	if top && limit.Offset > 0 {
+		builder.WriteString("SELECT * FROM (")
+	}
+
+	writeTop := func() {
+		if !top {
+			return
+		}
+		if limit.Offset > 0 || limit.Limit == nil || *limit.Limit < 0 {
+			builder.WriteString("TOP ALL ")
+		} else {
+			builder.WriteString("TOP ")
+			builder.WriteString(strconv.Itoa(*limit.Limit))
+			builder.WriteByte(' ')
+		}
+	}
+
+	switch s := c.Expression.(type) {
+	case clause.Select:
+		builder.WriteString("SELECT ")
+		if len(s.Columns) > 0 {
+			if s.Distinct {
+				builder.WriteString("DISTINCT ")
+			}
+			writeTop()
+
+			stmt, _ := builder.(*gorm.Statement)
+			for idx, column := range s.Columns {
+				if idx > 0 {
+					builder.WriteByte(',')
+				}
+				if field := dialector.convertedFieldOf(stmt, column); field != nil {
+					dialector.writeConvertedColumn(builder, column, field)
+					continue
+				}
+				if s.Distinct {
+					builder.WriteString("%EXACT(")
+					builder.WriteQuoted(column)
+					builder.WriteString(") AS ")
+				}
+				builder.WriteQuoted(column)
+			}
+		} else {
+			writeTop()
+			if !dialector.writeConvertedColumns(builder) {
+				builder.WriteByte('*')
+			}
+		}
+	default:
+		if !top {
+			c.Build(builder)
+			return
+		}
+		builder.WriteString("SELECT ")
+		if expr, ok := c.Expression.(clause.Expr); ok {
+			if sql, ok := strings.CutPrefix(expr.SQL, "DISTINCT "); ok {
+				builder.WriteString("DISTINCT ")
+				expr.SQL = sql
+			}
+			writeTop()
+			expr.Build(builder)
+			return
+		}
+		writeTop()
+		c.Expression.Build(builder)
+	}
+}
+
+// buildLimit writes LIMIT clause, or the end of the windowed subquery
+// started by buildSelect when LIMIT is emulated
+func (dialector Dialector) buildLimit(c clause.Clause, builder clause.Builder) {
+	limit, top := dialector.topLimit(builder)
+	if !top {
+		c.Build(builder)
+		return
+	}
+
+	if limit.Offset > 0 {
+		builder.WriteString(") WHERE %VID > ")
+		builder.WriteString(strconv.Itoa(limit.Offset))
+		if limit.Limit != nil && *limit.Limit >= 0 {
+			builder.WriteString(" AND %VID <= ")
+			builder.WriteString(strconv.Itoa(limit.Offset + *limit.Limit))
+		}
+	}
+}
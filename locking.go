@@ -0,0 +1,188 @@
+package iris
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+)
+
+// defaultLockTimeout is IRIS default LOCK_TIMEOUT in seconds, set back
+// after a lock when the one of the process can not be read
+const defaultLockTimeout = 10
+
+// ErrLockTimeout is returned, with TranslateError, when a row could not be
+// locked within the lock timeout.
+var ErrLockTimeout = errors.New("iris: lock timeout")
+
+var (
+	errSkipLockedNotSupported = errors.New("iris: SKIP LOCKED is not supported")
+	errShareLockNotSupported  = errors.New("iris: FOR SHARE is not supported, rows can only be locked for update")
+)
+
+// Lock is registered before gorm:query.
+// IRIS SELECT has no FOR UPDATE, but UPDATE locks the rows it changes until
+// the end of the transaction, so rows of a query with clause.Locking are
+// locked by an UPDATE that sets a column to itself, before being read.
+// Outside of a transaction the locks would be released right away, just as
+// with FOR UPDATE in autocommit mode, so nothing is done.
+//
+// As the rows are written, locking them fires UPDATE triggers, changes
+// %ROWVERSION and columns computed ON UPDATE, and fails in a read-only
+// transaction. Only the exclusive lock of FOR UPDATE can be taken, other
+// strengths are refused.
+func Lock(db *gorm.DB) {
+	if db.Error != nil || db.DryRun {
+		return
+	}
+
+	c, ok := db.Statement.Clauses["FOR"]
+	if !ok {
+		return
+	}
+	locking, ok := c.Expression.(clause.Locking)
+	if !ok {
+		return
+	}
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); !ok {
+		return
+	}
+	if !strings.EqualFold(locking.Strength, clause.LockingStrengthUpdate) {
+		db.AddError(errShareLockNotSupported)
+		return
+	}
+	if locking.Options == clause.LockingOptionsSkipLocked {
+		db.AddError(errSkipLockedNotSupported)
+		return
+	}
+
+	callbacks.BuildQuerySQL(db)
+	if db.Error != nil {
+		return
+	}
+
+	stmt := db.Statement
+	table := locking.Table.Name
+	if table == "" || table == clause.CurrentTable {
+		table = stmt.Table
+	}
+
+	var column string
+	if stmt.Schema != nil && stmt.Schema.Table == table {
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" && !field.PrimaryKey && !field.AutoIncrement && field.Updatable {
+				column = field.DBName
+				break
+			}
+		}
+	}
+	if column == "" {
+		db.AddError(fmt.Errorf("iris: no column to lock rows of %s with", table))
+		return
+	}
+
+	lockStmt := &gorm.Statement{
+		DB:       db,
+		ConnPool: stmt.ConnPool,
+		Context:  stmt.Context,
+		Table:    table,
+		Clauses:  map[string]clause.Clause{},
+	}
+	if table == stmt.Table {
+		lockStmt.TableExpr = stmt.TableExpr
+	}
+	lockStmt.AddClause(clause.Update{})
+	lockStmt.AddClause(clause.Set{{Column: clause.Column{Name: column}, Value: clause.Column{Name: column}}})
+	lockStmt.Build("UPDATE", "SET")
+
+	// the rows of a limited query are the first ones in its order, so the
+	// sub-select is limited and ordered too, with TOP as IRIS requires; its
+	// select list is written here, as the SELECT clause builder would write
+	// the columns of the query
+	var (
+		limit, _ = stmt.Clauses["LIMIT"].Expression.(clause.Limit)
+		limited  = (limit.Limit != nil && *limit.Limit >= 0) || limit.Offset > 0
+		rowTable = clause.Table{Name: clause.CurrentTable}
+	)
+	if table != stmt.Table {
+		rowTable = clause.Table{Name: table}
+	}
+
+	subStmt := &gorm.Statement{
+		DB:        db,
+		Context:   stmt.Context,
+		Table:     stmt.Table,
+		TableExpr: stmt.TableExpr,
+		Clauses:   map[string]clause.Clause{},
+	}
+	subStmt.WriteString("SELECT ")
+	switch {
+	case limit.Offset > 0:
+		subStmt.WriteString("TOP ALL ")
+	case limited:
+		subStmt.WriteString("TOP ")
+		subStmt.WriteString(strconv.Itoa(*limit.Limit))
+		subStmt.WriteByte(' ')
+	}
+	subStmt.AddVar(subStmt, rowIDOf(rowTable))
+	if limit.Offset > 0 {
+		subStmt.WriteString(" AS lock_id")
+	}
+	subStmt.WriteByte(' ')
+
+	clauses := []string{"FROM", "WHERE"}
+	if limited {
+		clauses = append(clauses, "ORDER BY")
+	}
+	for _, name := range clauses {
+		if c, ok := stmt.Clauses[name]; ok {
+			subStmt.Clauses[name] = c
+		}
+	}
+	subStmt.AddClauseIfNotExists(clause.From{})
+	subStmt.Build(clauses...)
+
+	lockStmt.WriteString(" WHERE %ID IN (")
+	if limit.Offset > 0 {
+		lockStmt.WriteString("SELECT lock_id FROM (")
+		lockStmt.WriteString(subStmt.SQL.String())
+		lockStmt.WriteString(") WHERE %VID > ")
+		lockStmt.WriteString(strconv.Itoa(limit.Offset))
+		if limit.Limit != nil && *limit.Limit >= 0 {
+			lockStmt.WriteString(" AND %VID <= ")
+			lockStmt.WriteString(strconv.Itoa(limit.Offset + *limit.Limit))
+		}
+	} else {
+		lockStmt.WriteString(subStmt.SQL.String())
+	}
+	lockStmt.Vars = append(lockStmt.Vars, subStmt.Vars...)
+	lockStmt.WriteByte(')')
+
+	lockTimeout := -1
+	if dialector, ok := db.Dialector.(*Dialector); ok && dialector.LockTimeout > 0 {
+		lockTimeout = dialector.LockTimeout
+	}
+	if locking.Options == clause.LockingOptionsNoWait {
+		lockTimeout = 0
+	}
+	if lockTimeout >= 0 {
+		previous := defaultLockTimeout
+		if err := stmt.ConnPool.QueryRowContext(stmt.Context, "SELECT $SYSTEM.SQL.Util.GetOption('ProcessLockTimeout')").Scan(&previous); err != nil {
+			previous = defaultLockTimeout
+		}
+		if _, err := stmt.ConnPool.ExecContext(stmt.Context, fmt.Sprintf("SET OPTION LOCK_TIMEOUT = %d", lockTimeout)); db.AddError(err) != nil {
+			return
+		}
+		defer func() {
+			_, err := stmt.ConnPool.ExecContext(stmt.Context, fmt.Sprintf("SET OPTION LOCK_TIMEOUT = %d", previous))
+			db.AddError(err)
+		}()
+	}
+
+	_, err := stmt.ConnPool.ExecContext(stmt.Context, lockStmt.SQL.String(), lockStmt.Vars...)
+	db.AddError(err)
+}
@@ -1,9 +1,15 @@
 package iris
 
 import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/migrator"
 	"gorm.io/gorm/schema"
 )
@@ -13,7 +19,69 @@ type Migrator struct {
 	Dialector
 }
 
-var defaultSchema = "SQLUser"
+// defaultSchema returns the schema of unqualified table names, from
+// DefaultSchemaSetting of the session or Config.DefaultSchema
+func (m Migrator) defaultSchema() string {
+	return m.Dialector.schemaOf(m.DB)
+}
+
+// CurrentTable implements gorm.Migrator.
+// Subtle: this method shadows the method (Migrator).CurrentTable of Migrator.Migrator.
+// An unqualified table is qualified with the default schema, when it is
+// not the default schema of the connection.
+func (m Migrator) CurrentTable(stmt *gorm.Statement) interface{} {
+	if expr, ok := m.Dialector.qualifiedTable(stmt, m.defaultSchema()); ok {
+		return expr
+	}
+	return m.Migrator.CurrentTable(stmt)
+}
+
+// scoped returns the migrator of the table of value, qualified as
+// CurrentTable does, for the methods of migrator.Migrator, which write the
+// table as they get it.
+func (m Migrator) scoped(value interface{}) Migrator {
+	var table string
+	m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		if _, ok := m.Dialector.qualifiedTable(stmt, m.defaultSchema()); ok {
+			table = stmt.Table
+		}
+		return nil
+	})
+	if table != "" {
+		m.DB = m.DB.Table(m.defaultSchema() + "." + table)
+	}
+	return m
+}
+
+// checkDataTypes returns an error when a column of value, or only field when
+// given, has a data type the connected IRIS does not support
+func (m Migrator) checkDataTypes(value interface{}, field string) error {
+	if m.Dialector.Supports(FeatureVector) {
+		return nil
+	}
+	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		if stmt.Schema == nil {
+			return nil
+		}
+		fields := stmt.Schema.Fields
+		if field != "" {
+			if f := stmt.Schema.LookUpField(field); f != nil {
+				fields = []*schema.Field{f}
+			} else {
+				fields = nil
+			}
+		}
+		for _, f := range fields {
+			if f.DBName == "" || f.IgnoreMigration {
+				continue
+			}
+			if dataType := m.Migrator.DataTypeOf(f); strings.HasPrefix(strings.ToLower(dataType), "vector") {
+				return fmt.Errorf("iris: column %s of %s is %s, which needs IRIS %s or later", f.DBName, stmt.Table, dataType, featureVersions[FeatureVector])
+			}
+		}
+		return nil
+	})
+}
 
 func (m Migrator) queryRaw(sql string, values ...interface{}) (tx *gorm.DB) {
 	queryTx := m.DB
@@ -28,37 +96,291 @@ func (m Migrator) queryRaw(sql string, values ...interface{}) (tx *gorm.DB) {
 // AddColumn implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).AddColumn of Migrator.Migrator.
 func (m Migrator) AddColumn(dst interface{}, field string) error {
-	return m.Migrator.AddColumn(dst, field)
+	if err := m.checkDataTypes(dst, field); err != nil {
+		return err
+	}
+	return m.scoped(dst).Migrator.AddColumn(dst, field)
 }
 
 // AlterColumn implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).AlterColumn of Migrator.Migrator.
+// IRIS alters type, nullability and default of a column with separate
+// statements; nullability and default are altered only when they differ
+// from the table.
 func (m Migrator) AlterColumn(dst interface{}, field string) error {
-	return m.Migrator.AlterColumn(dst, field)
+	if err := m.checkDataTypes(dst, field); err != nil {
+		return err
+	}
+	return m.RunWithValue(dst, func(stmt *gorm.Statement) error {
+		if stmt.Schema == nil {
+			return fmt.Errorf("failed to look up field with name: %s", field)
+		}
+		f := stmt.Schema.LookUpField(field)
+		if f == nil {
+			return fmt.Errorf("failed to look up field with name: %s", field)
+		}
+
+		var current gorm.ColumnType
+		if columnTypes, err := m.ColumnTypes(dst); err == nil {
+			for _, columnType := range columnTypes {
+				if strings.EqualFold(columnType.Name(), f.DBName) {
+					current = columnType
+					break
+				}
+			}
+		}
+
+		table, column := m.CurrentTable(stmt), clause.Column{Name: f.DBName}
+		if dataType := m.Migrator.DataTypeOf(f); !f.AutoIncrement && !keepsColumn(m.Dialector, f, current) && !sameColumnType(current, dataType) {
+			if err := m.DB.Exec("ALTER TABLE ? ALTER COLUMN ? ?", table, column, clause.Expr{SQL: dataType}).Error; err != nil {
+				return fmt.Errorf("iris: cannot convert column %s of %s to %s: %w", f.DBName, stmt.Table, dataType, err)
+			}
+		}
+
+		notNull := f.NotNull || f.PrimaryKey
+		if nullable, ok := columnTypeNullable(current); !ok || nullable == notNull {
+			nullability := "NULL"
+			if notNull {
+				nullability = "NOT NULL"
+			}
+			if err := m.DB.Exec("ALTER TABLE ? ALTER COLUMN ? "+nullability, table, column).Error; err != nil {
+				return err
+			}
+		}
+
+		defaultValue, hasDefault := m.Dialector.defaultValue(f)
+		currentDefault, currentHasDefault := columnTypeDefault(current)
+		switch {
+		case hasDefault && (!currentHasDefault || !sameDefaultValue(f, defaultValue, currentDefault)):
+			return m.DB.Exec("ALTER TABLE ? ALTER COLUMN ? DEFAULT ?", table, column, clause.Expr{SQL: defaultValue}).Error
+		case !hasDefault && currentHasDefault:
+			return m.DB.Exec("ALTER TABLE ? ALTER COLUMN ? DROP DEFAULT", table, column).Error
+		}
+		return nil
+	})
+}
+
+// columnTypeRegexp parses data types like varchar(100) or numeric(10,2)
+var columnTypeRegexp = regexp.MustCompile(`^\s*([a-z]+)\s*(?:\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\))?\s*$`)
+
+// sameColumnType reports whether the column is already of dataType, so
+// that it is not converted again
+func sameColumnType(current gorm.ColumnType, dataType string) bool {
+	if current == nil {
+		return false
+	}
+	matches := columnTypeRegexp.FindStringSubmatch(strings.ToLower(dataType))
+	if matches == nil {
+		return false
+	}
+
+	name := strings.ToLower(current.DatabaseTypeName())
+	if name != matches[1] {
+		aliased := false
+		for _, alias := range typeAliases[name] {
+			if alias == matches[1] {
+				aliased = true
+				break
+			}
+		}
+		if !aliased {
+			return false
+		}
+	}
+
+	if matches[2] == "" {
+		return true
+	}
+	size, _ := strconv.ParseInt(matches[2], 10, 64)
+	if precision, currentScale, ok := current.DecimalSize(); ok && (name == "numeric" || name == "decimal") {
+		var scale int64
+		if matches[3] != "" {
+			scale, _ = strconv.ParseInt(matches[3], 10, 64)
+		}
+		return precision == size && currentScale == scale
+	}
+	length, ok := current.Length()
+	return ok && length == size
+}
+
+func columnTypeNullable(columnType gorm.ColumnType) (bool, bool) {
+	if columnType == nil {
+		return false, false
+	}
+	return columnType.Nullable()
+}
+
+func columnTypeDefault(columnType gorm.ColumnType) (string, bool) {
+	if columnType == nil {
+		return "", false
+	}
+	return columnType.DefaultValue()
 }
 
 // ColumnTypes implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).ColumnTypes of Migrator.Migrator.
 func (m Migrator) ColumnTypes(dst interface{}) ([]gorm.ColumnType, error) {
-	return m.Migrator.ColumnTypes(dst)
+	columnTypes := make([]gorm.ColumnType, 0)
+	execErr := m.RunWithValue(dst, func(stmt *gorm.Statement) (err error) {
+		currentSchema, currentTable := m.CurrentSchema(stmt, stmt.Table)
+		columns, err := m.queryRaw(
+			"SELECT column_name, column_default, is_nullable, data_type, character_maximum_length, numeric_precision, numeric_scale, auto_increment, is_identity, primary_key, unique_column, description FROM INFORMATION_SCHEMA.columns WHERE table_schema = ? AND table_name = ? ORDER BY ordinal_position",
+			currentSchema, currentTable,
+		).Rows()
+		if err != nil {
+			return err
+		}
+		defer columns.Close()
+
+		var infoTypes []migrator.ColumnType
+		for columns.Next() {
+			var (
+				column                                        migrator.ColumnType
+				nullable, autoIncrement, identity, pk, unique sql.NullString
+				length, precision, scale                      sql.NullInt64
+			)
+			if err = columns.Scan(
+				&column.NameValue, &column.DefaultValueValue, &nullable, &column.DataTypeValue,
+				&length, &precision, &scale, &autoIncrement, &identity, &pk, &unique, &column.CommentValue,
+			); err != nil {
+				return err
+			}
+
+			dataType := strings.ToLower(column.DataTypeValue.String)
+			column.DataTypeValue.String = dataType
+			column.ColumnTypeValue = sql.NullString{String: dataType, Valid: true}
+			switch dataType {
+			case "numeric", "decimal":
+				column.DecimalSizeValue, column.ScaleValue = precision, scale
+				if precision.Valid {
+					column.ColumnTypeValue.String = fmt.Sprintf("%s(%d,%d)", dataType, precision.Int64, scale.Int64)
+				}
+			default:
+				if length.Valid {
+					column.LengthValue = length
+					column.ColumnTypeValue.String = fmt.Sprintf("%s(%d)", dataType, length.Int64)
+				}
+			}
+			column.NullableValue = sql.NullBool{Bool: nullable.String == "YES", Valid: true}
+			column.AutoIncrementValue = sql.NullBool{Bool: autoIncrement.String == "YES" || identity.String == "YES", Valid: true}
+			column.PrimaryKeyValue = sql.NullBool{Bool: pk.String == "YES", Valid: true}
+			column.UniqueValue = sql.NullBool{Bool: unique.String == "YES", Valid: true}
+			if column.DefaultValueValue.String == "" {
+				column.DefaultValueValue.Valid = false
+			}
+			if column.CommentValue.String == "" {
+				column.CommentValue.Valid = false
+			}
+			infoTypes = append(infoTypes, column)
+		}
+		if err = columns.Err(); err != nil {
+			return err
+		}
+
+		queryTx := m.DB.Session(&gorm.Session{})
+		queryTx.DryRun = false
+		rows, err := queryTx.Table(fmt.Sprintf("%v.%v", currentSchema, currentTable)).Limit(1).Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		rawColumnTypes, err := rows.ColumnTypes()
+		if err != nil {
+			return err
+		}
+
+		for _, c := range rawColumnTypes {
+			columnType := migrator.ColumnType{SQLColumnType: c}
+			for _, infoType := range infoTypes {
+				if strings.EqualFold(infoType.NameValue.String, c.Name()) {
+					infoType.SQLColumnType = c
+					columnType = infoType
+					break
+				}
+			}
+			columnTypes = append(columnTypes, columnType)
+		}
+
+		return
+	})
+
+	return columnTypes, execErr
 }
 
 // CreateConstraint implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).CreateConstraint of Migrator.Migrator.
 func (m Migrator) CreateConstraint(dst interface{}, name string) error {
-	return m.Migrator.CreateConstraint(dst, name)
+	return m.scoped(dst).Migrator.CreateConstraint(dst, name)
 }
 
 // CreateIndex implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).CreateIndex of Migrator.Migrator.
 func (m Migrator) CreateIndex(dst interface{}, name string) error {
-	return m.Migrator.CreateIndex(dst, name)
+	if ifNotExists, _ := m.DB.Get(ifNotExistsSetting); ifNotExists == true && m.HasIndex(dst, name) {
+		return nil
+	}
+	return m.scoped(dst).Migrator.CreateIndex(dst, name)
+}
+
+// ifNotExistsSetting marks the sessions of CreateTable on releases with
+// CREATE TABLE IF NOT EXISTS
+const ifNotExistsSetting = "iris:if_not_exists"
+
+// createTableIfNotExists is registered before gorm:raw, it makes CREATE
+// TABLE of CreateTable CREATE TABLE IF NOT EXISTS.
+func createTableIfNotExists(db *gorm.DB) {
+	if ifNotExists, _ := db.Get(ifNotExistsSetting); ifNotExists != true {
+		return
+	}
+	if sql, ok := strings.CutPrefix(db.Statement.SQL.String(), "CREATE TABLE "); ok {
+		db.Statement.SQL.Reset()
+		db.Statement.SQL.WriteString("CREATE TABLE IF NOT EXISTS ")
+		db.Statement.SQL.WriteString(sql)
+	}
+}
+
+// tableOptions returns gorm:table_options of the tables to create, with the
+// storage type of ColumnarStorageSetting
+func (m Migrator) tableOptions() (string, error) {
+	value, _ := m.DB.Get("gorm:table_options")
+	options, _ := value.(string)
+	if columnar, _ := m.DB.Get(ColumnarStorageSetting); columnar != true {
+		return options, nil
+	}
+	if !m.Dialector.Supports(FeatureColumnarStorage) {
+		return "", fmt.Errorf("iris: columnar storage needs IRIS %s or later", featureVersions[FeatureColumnarStorage])
+	}
+	if strings.Contains(strings.ToUpper(options), "WITH ") {
+		return options + ", STORAGETYPE = COLUMNAR", nil
+	}
+	return options + " WITH STORAGETYPE = COLUMNAR", nil
 }
 
 // CreateTable implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).CreateTable of Migrator.Migrator.
+// Tables are created with IF NOT EXISTS when the release has it, an
+// existing table is then left as it is, with its indexes, and with columnar
+// storage with ColumnarStorageSetting.
 func (m Migrator) CreateTable(values ...interface{}) error {
-	return m.Migrator.CreateTable(values...)
+	options, err := m.tableOptions()
+	if err != nil {
+		return err
+	}
+	m.DB = m.DB.Session(&gorm.Session{}).Set("gorm:table_options", options)
+	if m.Dialector.Supports(FeatureCreateTableIfNotExists) {
+		m.DB = m.DB.Set(ifNotExistsSetting, true)
+	}
+
+	for _, value := range m.ReorderModels(values, false) {
+		if err := m.checkDataTypes(value, ""); err != nil {
+			return err
+		}
+		if err := m.scoped(value).Migrator.CreateTable(value); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // CreateView implements gorm.Migrator.
@@ -69,26 +391,34 @@ func (m Migrator) CreateView(name string, option gorm.ViewOption) error {
 
 // CurrentDatabase implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).CurrentDatabase of Migrator.Migrator.
-func (m Migrator) CurrentDatabase() string {
+func (m Migrator) CurrentDatabase() (name string) {
+	if m.queryRaw("SELECT $NAMESPACE").Row().Scan(&name) == nil && name != "" {
+		return name
+	}
+	if m.Dialector.Config != nil {
+		if u, err := url.Parse(m.Dialector.DSN); err == nil {
+			return strings.Trim(u.Path, "/")
+		}
+	}
 	return ""
 }
 
 // DropColumn implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).DropColumn of Migrator.Migrator.
 func (m Migrator) DropColumn(dst interface{}, field string) error {
-	return m.Migrator.DropColumn(dst, field)
+	return m.scoped(dst).Migrator.DropColumn(dst, field)
 }
 
 // DropConstraint implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).DropConstraint of Migrator.Migrator.
 func (m Migrator) DropConstraint(dst interface{}, name string) error {
-	return m.Migrator.DropConstraint(dst, name)
+	return m.scoped(dst).Migrator.DropConstraint(dst, name)
 }
 
 // DropIndex implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).DropIndex of Migrator.Migrator.
 func (m Migrator) DropIndex(dst interface{}, name string) error {
-	return m.Migrator.DropIndex(dst, name)
+	return m.scoped(dst).Migrator.DropIndex(dst, name)
 }
 
 // DropTable implements gorm.Migrator.
@@ -116,19 +446,106 @@ func (m Migrator) DropView(name string) error {
 // GetIndexes implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).GetIndexes of Migrator.Migrator.
 func (m Migrator) GetIndexes(dst interface{}) ([]gorm.Index, error) {
-	return m.Migrator.GetIndexes(dst)
+	indexes := make([]gorm.Index, 0)
+	err := m.RunWithValue(dst, func(stmt *gorm.Statement) error {
+		currentSchema, currentTable := m.CurrentSchema(stmt, stmt.Table)
+		rows, err := m.queryRaw(
+			"SELECT i.index_name, i.column_name, i.non_unique, i.primary_key, ci.Type FROM INFORMATION_SCHEMA.indexes i JOIN INFORMATION_SCHEMA.tables t ON t.table_schema = i.table_schema AND t.table_name = i.table_name LEFT JOIN %Dictionary.CompiledIndex ci ON ci.parent = t.classname AND ci.SqlName = i.index_name WHERE i.table_schema = ? AND i.table_name = ? ORDER BY i.index_name, i.ordinal_position",
+			currentSchema, currentTable,
+		).Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		byName := map[string]*Index{}
+		var names []string
+		for rows.Next() {
+			var (
+				name, column  string
+				nonUnique     sql.NullInt64
+				pk, indexType sql.NullString
+			)
+			if err := rows.Scan(&name, &column, &nonUnique, &pk, &indexType); err != nil {
+				return err
+			}
+			idx, ok := byName[name]
+			if !ok {
+				idx = &Index{
+					Index: migrator.Index{
+						TableName:       stmt.Table,
+						NameValue:       name,
+						PrimaryKeyValue: sql.NullBool{Bool: pk.String == "YES", Valid: true},
+						UniqueValue:     sql.NullBool{Bool: nonUnique.Int64 == 0, Valid: nonUnique.Valid},
+					},
+					TypeValue: indexTypes[strings.ToLower(indexType.String)],
+				}
+				byName[name] = idx
+				names = append(names, name)
+			}
+			idx.ColumnList = append(idx.ColumnList, column)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			indexes = append(indexes, *byName[name])
+		}
+		return nil
+	})
+	return indexes, err
+}
+
+// Index is an index read by GetIndexes
+type Index struct {
+	migrator.Index
+	TypeValue string
+}
+
+// Type returns IRIS index type: bitmap, bitslice or standard
+func (idx Index) Type() string {
+	return idx.TypeValue
+}
+
+// indexTypes maps %Dictionary.CompiledIndex types to Index.Type
+var indexTypes = map[string]string{
+	"":         "standard",
+	"index":    "standard",
+	"key":      "standard",
+	"bitmap":   "bitmap",
+	"bitslice": "bitslice",
 }
 
 // GetTables implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).GetTables of Migrator.Migrator.
 func (m Migrator) GetTables() (tableList []string, err error) {
-	return m.Migrator.GetTables()
+	err = m.queryRaw(
+		"SELECT table_name FROM INFORMATION_SCHEMA.tables WHERE table_schema = ? AND table_type = ?",
+		m.defaultSchema(), "BASE TABLE",
+	).Scan(&tableList).Error
+	return
 }
 
 // GetTypeAliases implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).GetTypeAliases of Migrator.Migrator.
 func (m Migrator) GetTypeAliases(databaseTypeName string) []string {
-	return m.Migrator.GetTypeAliases(databaseTypeName)
+	return typeAliases[databaseTypeName]
+}
+
+// typeAliases maps data types reported by INFORMATION_SCHEMA to the ones
+// produced by DataTypeOf
+var typeAliases = map[string][]string{
+	"bit":       {"bool", "boolean"},
+	"integer":   {"int", "identity"},
+	"bigint":    {"identity"},
+	"numeric":   {"decimal"},
+	"decimal":   {"numeric"},
+	"varbinary": {"binary"},
+	"binary":    {"varbinary"},
+	"timestamp": {"datetime", "posixtime"},
+	"varchar":   {"uniqueidentifier"},
+	"posixtime": {"timestamp"},
 }
 
 // HasColumn implements gorm.Migrator.
@@ -150,7 +567,8 @@ func (m Migrator) HasColumn(dst interface{}, field string) bool {
 		).Row().Scan(&count)
 	})
 
-	return count > 0}
+	return count > 0
+}
 
 // HasConstraint implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).HasConstraint of Migrator.Migrator.
@@ -161,7 +579,22 @@ func (m Migrator) HasConstraint(dst interface{}, name string) bool {
 // HasIndex implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).HasIndex of Migrator.Migrator.
 func (m Migrator) HasIndex(dst interface{}, name string) bool {
-	return m.Migrator.HasIndex(dst, name)
+	var count int64
+	m.RunWithValue(dst, func(stmt *gorm.Statement) error {
+		currentSchema, currentTable := m.CurrentSchema(stmt, stmt.Table)
+		if stmt.Schema != nil {
+			if idx := stmt.Schema.LookIndex(name); idx != nil {
+				name = idx.Name
+			}
+		}
+
+		return m.queryRaw(
+			"SELECT count(*) FROM INFORMATION_SCHEMA.indexes WHERE table_schema = ? AND table_name = ? AND index_name = ?",
+			currentSchema, currentTable, name,
+		).Row().Scan(&count)
+	})
+
+	return count > 0
 }
 
 func (m Migrator) CurrentSchema(stmt *gorm.Statement, table string) (interface{}, interface{}) {
@@ -176,7 +609,7 @@ func (m Migrator) CurrentSchema(stmt *gorm.Statement, table string) (interface{}
 			return strings.TrimPrefix(tables[0], `"`), table
 		}
 	}
-	return defaultSchema, table
+	return m.defaultSchema(), table
 }
 
 // HasTable implements gorm.Migrator.
@@ -194,9 +627,103 @@ func (m Migrator) HasTable(value interface{}) bool {
 // MigrateColumn implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).MigrateColumn of Migrator.Migrator.
 func (m Migrator) MigrateColumn(dst interface{}, field *schema.Field, columnType gorm.ColumnType) error {
+	// IRIS reports defaults in its own form, an equal one is reported as
+	// written in the model, so that the column is not altered again
+	if current, ok := columnType.DefaultValue(); ok {
+		if defaultValue, ok := m.Dialector.defaultValue(field); ok && sameDefaultValue(field, defaultValue, current) {
+			columnType = defaultValueColumnType{columnType: columnType, defaultValue: field.DefaultValue}
+		}
+	}
+	if keepsColumn(m.Dialector, field, columnType) {
+		columnType = streamColumnType{columnType: columnType, dataType: strings.ToLower(m.Dialector.DataTypeOf(field))}
+	}
 	return m.Migrator.MigrateColumn(dst, field, columnType)
 }
 
+// keepsColumn reports whether the column of a stream field is kept as it
+// is, IRIS can not convert an existing varchar or binary column to a stream
+func keepsColumn(dialector Dialector, field *schema.Field, current gorm.ColumnType) bool {
+	return current != nil && dialector.isStream(field) && !isStreamType(current.DatabaseTypeName())
+}
+
+// FullDataTypeOf implements gorm.Migrator.
+// Subtle: this method shadows the method (Migrator).FullDataTypeOf of Migrator.Migrator.
+func (m Migrator) FullDataTypeOf(field *schema.Field) (expr clause.Expr) {
+	expr.SQL = m.Migrator.DataTypeOf(field)
+	if field.NotNull {
+		expr.SQL += " NOT NULL"
+	}
+	if defaultValue, ok := m.Dialector.defaultValue(field); ok {
+		expr.SQL += " DEFAULT " + defaultValue
+	}
+	return
+}
+
+type columnType = gorm.ColumnType
+
+// defaultValueColumnType is a column type with another DefaultValue
+type defaultValueColumnType struct {
+	columnType
+	defaultValue string
+}
+
+func (c defaultValueColumnType) DefaultValue() (string, bool) {
+	return c.defaultValue, true
+}
+
+// streamColumnType is a column kept as the stream type of its field
+type streamColumnType struct {
+	columnType
+	dataType string
+}
+
+func (c streamColumnType) DatabaseTypeName() string {
+	return c.dataType
+}
+
+func (c streamColumnType) Length() (int64, bool) {
+	return 0, false
+}
+
+// nowFunctions are the defaults IRIS evaluates to the current timestamp
+var nowFunctions = map[string]bool{
+	"current_timestamp": true,
+	"getdate":           true,
+	"now":               true,
+	"{fn now}":          true,
+	"sysdate":           true,
+}
+
+// sameDefaultValue reports whether DEFAULT of the field, as written in DDL,
+// is the default reported by INFORMATION_SCHEMA
+func sameDefaultValue(field *schema.Field, defaultValue, current string) bool {
+	normalize := func(value string) string {
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') && value[len(value)-1] == value[0] {
+			quote := value[:1]
+			value = strings.ReplaceAll(value[1:len(value)-1], quote+quote, quote)
+		}
+		value = strings.ToLower(value)
+		value = strings.ReplaceAll(value, "()", "")
+		if nowFunctions[value] {
+			return "current_timestamp"
+		}
+		return value
+	}
+
+	defaultValue, current = normalize(defaultValue), normalize(current)
+	if defaultValue == current {
+		return true
+	}
+	switch field.DataType {
+	case schema.Bool, schema.Int, schema.Uint, schema.Float:
+		v1, err1 := strconv.ParseFloat(defaultValue, 64)
+		v2, err2 := strconv.ParseFloat(current, 64)
+		return err1 == nil && err2 == nil && v1 == v2
+	}
+	return false
+}
+
 // MigrateColumnUnique implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).MigrateColumnUnique of Migrator.Migrator.
 func (m Migrator) MigrateColumnUnique(dst interface{}, field *schema.Field, columnType gorm.ColumnType) error {
@@ -206,19 +733,99 @@ func (m Migrator) MigrateColumnUnique(dst interface{}, field *schema.Field, colu
 // RenameColumn implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).RenameColumn of Migrator.Migrator.
 func (m Migrator) RenameColumn(dst interface{}, oldName string, field string) error {
-	return m.Migrator.RenameColumn(dst, oldName, field)
+	return m.RunWithValue(dst, func(stmt *gorm.Statement) error {
+		if stmt.Schema != nil {
+			if f := stmt.Schema.LookUpField(oldName); f != nil {
+				oldName = f.DBName
+			}
+			if f := stmt.Schema.LookUpField(field); f != nil {
+				field = f.DBName
+			}
+		}
+
+		return m.DB.Exec(
+			"ALTER TABLE ? ALTER COLUMN ? RENAME ?",
+			m.CurrentTable(stmt), clause.Column{Name: oldName}, clause.Column{Name: field},
+		).Error
+	})
 }
 
 // RenameIndex implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).RenameIndex of Migrator.Migrator.
+// IRIS cannot rename an index, so it is dropped and created again, as
+// defined by the model or, when the model has no such index, as it was.
 func (m Migrator) RenameIndex(dst interface{}, oldName string, newName string) error {
-	return m.Migrator.RenameIndex(dst, oldName, newName)
+	return m.RunWithValue(dst, func(stmt *gorm.Statement) error {
+		if stmt.Schema != nil {
+			if idx := stmt.Schema.LookIndex(newName); idx != nil {
+				if err := m.DropIndex(dst, oldName); err != nil {
+					return err
+				}
+				return m.CreateIndex(dst, newName)
+			}
+		}
+
+		indexes, err := m.GetIndexes(dst)
+		if err != nil {
+			return err
+		}
+		var old *Index
+		for _, idx := range indexes {
+			if idx, ok := idx.(Index); ok && strings.EqualFold(idx.Name(), oldName) {
+				old = &idx
+				break
+			}
+		}
+		if old == nil {
+			return fmt.Errorf("failed to look up index with name: %s", oldName)
+		}
+
+		if err := m.DropIndex(dst, oldName); err != nil {
+			return err
+		}
+
+		createIndexSQL := "CREATE "
+		if unique, _ := old.Unique(); unique {
+			createIndexSQL += "UNIQUE "
+		}
+		if old.Type() != "standard" {
+			createIndexSQL += strings.ToUpper(old.Type()) + " "
+		}
+		columns := make([]interface{}, len(old.ColumnList))
+		for i, column := range old.ColumnList {
+			columns[i] = clause.Column{Name: column}
+		}
+		return m.DB.Exec(createIndexSQL+"INDEX ? ON ??", clause.Column{Name: newName}, m.CurrentTable(stmt), columns).Error
+	})
 }
 
 // RenameTable implements gorm.Migrator.
 // Subtle: this method shadows the method (Migrator).RenameTable of Migrator.Migrator.
 func (m Migrator) RenameTable(oldName interface{}, newName interface{}) error {
-	return m.Migrator.RenameTable(oldName, newName)
+	oldStmt, err := m.tableOf(oldName)
+	if err != nil {
+		return err
+	}
+	newStmt, err := m.tableOf(newName)
+	if err != nil {
+		return err
+	}
+	// the table is renamed in its schema
+	return m.DB.Exec("ALTER TABLE ? RENAME ?", m.CurrentTable(oldStmt), clause.Table{Name: newStmt.Table}).Error
+}
+
+// tableOf returns the statement of the table of a model, or of the table
+// name
+func (m Migrator) tableOf(value interface{}) (*gorm.Statement, error) {
+	stmt := &gorm.Statement{DB: m.DB}
+	if name, ok := value.(string); ok {
+		stmt.Table = name
+		return stmt, nil
+	}
+	if err := stmt.Parse(value); err != nil {
+		return nil, err
+	}
+	return stmt, nil
 }
 
 // TableType implements gorm.Migrator.
@@ -0,0 +1,291 @@
+package iris
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+)
+
+// rowIDColumn is the RowID of the current table
+var rowIDColumn = rowIDOf(clause.Table{Name: clause.CurrentTable})
+
+// rowIDOf returns the RowID of table, which is quoted as in FROM, as %ID
+// is not a column name to quote
+func rowIDOf(table clause.Table) clause.Expr {
+	return clause.Expr{SQL: "?.%ID", Vars: []interface{}{table}}
+}
+
+// returningOf returns RETURNING clause of the statement, if any.
+func returningOf(stmt *gorm.Statement) (clause.Returning, bool) {
+	if c, ok := stmt.Clauses["RETURNING"]; ok {
+		returning, ok := c.Expression.(clause.Returning)
+		return returning, ok
+	}
+	return clause.Returning{}, false
+}
+
+// returningScanMode is the gorm.ScanMode of the rows read for returning,
+// as gorm callbacks do for dialects with RETURNING.
+func returningScanMode(returning clause.Returning) gorm.ScanMode {
+	if len(returning.Columns) == 0 || (len(returning.Columns) == 1 && returning.Columns[0].Name == "*") {
+		return 0
+	}
+	return gorm.ScanUpdate
+}
+
+// UpdateWithReturning replaces gorm:update callback.
+// IRIS has no RETURNING, so with clause.Returning the RowIDs of the rows to
+// update are selected first, in the same transaction, then the rows with
+// these RowIDs are updated and their returned columns are read.
+func UpdateWithReturning(config *callbacks.Config) func(db *gorm.DB) {
+	update := callbacks.Update(config)
+
+	return func(db *gorm.DB) {
+		returning, ok := returningOf(db.Statement)
+		if !ok || db.Error != nil || db.DryRun {
+			update(db)
+			return
+		}
+
+		inTransaction(db, func() {
+			// builds the statement, which sets its WHERE clause
+			dryRun := db.Session(&gorm.Session{DryRun: true})
+			if update(dryRun); db.AddError(dryRun.Error) != nil {
+				return
+			}
+			ids, err := affectedRowIDs(db)
+			if db.AddError(err) != nil {
+				return
+			}
+
+			if len(ids) == 0 {
+				return
+			}
+			whereRowIDs(db.Statement, ids)
+			if update(db); db.Error != nil {
+				return
+			}
+
+			rows, err := selectReturning(db, returning, ids)
+			if db.AddError(err) != nil {
+				return
+			}
+			defer func() {
+				db.AddError(rows.Close())
+			}()
+
+			rowsAffected, dest := db.RowsAffected, db.Statement.Dest
+			if db.Statement.ReflectValue.CanAddr() {
+				db.Statement.Dest = db.Statement.ReflectValue.Addr().Interface()
+			}
+			gorm.Scan(rows, db, returningScanMode(returning))
+			db.RowsAffected, db.Statement.Dest = rowsAffected, dest
+		})
+	}
+}
+
+// DeleteWithReturning replaces gorm:delete callback.
+// With clause.Returning the RowIDs of the rows to delete are selected and
+// their returned columns are read, then the rows with these RowIDs are
+// deleted, in the same transaction.
+func DeleteWithReturning(config *callbacks.Config) func(db *gorm.DB) {
+	deleteRows := callbacks.Delete(config)
+
+	return func(db *gorm.DB) {
+		returning, ok := returningOf(db.Statement)
+		if !ok || db.Error != nil || db.DryRun {
+			deleteRows(db)
+			return
+		}
+
+		inTransaction(db, func() {
+			dryRun := db.Session(&gorm.Session{DryRun: true})
+			if deleteRows(dryRun); db.AddError(dryRun.Error) != nil {
+				return
+			}
+			ids, err := affectedRowIDs(db)
+			if db.AddError(err) != nil {
+				return
+			}
+
+			if len(ids) == 0 {
+				return
+			}
+			rows, err := selectReturning(db, returning, ids)
+			if db.AddError(err) != nil {
+				return
+			}
+			gorm.Scan(rows, db, returningScanMode(returning))
+			if db.AddError(rows.Close()) != nil {
+				return
+			}
+
+			whereRowIDs(db.Statement, ids)
+			deleteRows(db)
+		})
+	}
+}
+
+// whereRowIDs narrows the conditions of the statement built in dry run mode
+// to the RowIDs selected for returning, as rows committed since the select
+// may match them too, and resets its SQL to build it again.
+func whereRowIDs(stmt *gorm.Statement, ids []interface{}) {
+	stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: rowIDColumn, Values: ids}}})
+	stmt.SQL.Reset()
+	stmt.Vars = nil
+}
+
+// scanReturning reads the returned columns of the row just inserted into
+// dest, a map or the struct of the row.
+func scanReturning(db *gorm.DB, returning clause.Returning, id int64, dest reflect.Value) {
+	if dest.Kind() != reflect.Map && reflect.Indirect(dest).Kind() != reflect.Struct {
+		return
+	}
+	rows, err := selectReturning(db, returning, []interface{}{id})
+	if db.AddError(err) != nil {
+		return
+	}
+	defer func() {
+		db.AddError(rows.Close())
+	}()
+
+	stmt := db.Statement
+	rowsAffected, reflectValue, value := db.RowsAffected, stmt.ReflectValue, stmt.Dest
+	if dest.Kind() == reflect.Map {
+		stmt.Dest = dest.Interface()
+	} else {
+		stmt.ReflectValue = reflect.Indirect(dest)
+		stmt.Dest = stmt.ReflectValue.Addr().Interface()
+	}
+	gorm.Scan(rows, db, gorm.ScanUpdate)
+	db.RowsAffected, stmt.ReflectValue, stmt.Dest = rowsAffected, reflectValue, value
+}
+
+// affectedRowIDs selects the RowIDs of the rows matching the conditions of
+// the statement.
+func affectedRowIDs(db *gorm.DB) (ids []interface{}, err error) {
+	stmt := db.Statement
+	selectStmt := &gorm.Statement{
+		DB:        db,
+		Context:   stmt.Context,
+		Table:     stmt.Table,
+		TableExpr: stmt.TableExpr,
+		Clauses:   map[string]clause.Clause{},
+	}
+	selectStmt.AddClause(clause.Select{Expression: rowIDColumn})
+	for _, name := range []string{"FROM", "WHERE"} {
+		if c, ok := stmt.Clauses[name]; ok {
+			selectStmt.Clauses[name] = c
+		}
+	}
+	selectStmt.AddClauseIfNotExists(clause.From{})
+	selectStmt.Build("SELECT", "FROM", "WHERE")
+
+	rows, err := stmt.ConnPool.QueryContext(stmt.Context, selectStmt.SQL.String(), selectStmt.Vars...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var value interface{}
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		id, ok := rowID(value)
+		if !ok {
+			return nil, fmt.Errorf("iris: RETURNING needs an integer RowID, %s has %v", stmt.Table, value)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// rowID converts a RowID as read by the driver to int64, ok is false when
+// it is not an integer, as with an IDKEY on other columns
+func rowID(value interface{}) (id int64, ok bool) {
+	switch value := value.(type) {
+	case int64:
+		return value, true
+	case int32:
+		return int64(value), true
+	case int:
+		return int64(value), true
+	case string:
+		id, err := strconv.ParseInt(value, 10, 64)
+		return id, err == nil
+	case []byte:
+		id, err := strconv.ParseInt(string(value), 10, 64)
+		return id, err == nil
+	}
+	return 0, false
+}
+
+// selectReturning selects the columns of returning of the rows with ids.
+func selectReturning(db *gorm.DB, returning clause.Returning, ids []interface{}) (*sql.Rows, error) {
+	stmt := db.Statement
+	selectStmt := &gorm.Statement{
+		DB:        db,
+		Context:   stmt.Context,
+		Table:     stmt.Table,
+		TableExpr: stmt.TableExpr,
+		Schema:    stmt.Schema,
+		Clauses:   map[string]clause.Clause{},
+	}
+
+	var columns []clause.Column
+	if returningScanMode(returning) != 0 {
+		columns = returning.Columns
+	}
+	selectStmt.AddClause(clause.Select{Columns: columns})
+	selectStmt.AddClause(clause.From{})
+	selectStmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: rowIDColumn, Values: ids}}})
+	selectStmt.AddClause(clause.OrderBy{Expression: rowIDColumn})
+	selectStmt.Build("SELECT", "FROM", "WHERE", "ORDER BY")
+
+	return stmt.ConnPool.QueryContext(stmt.Context, selectStmt.SQL.String(), selectStmt.Vars...)
+}
+
+// inTransaction runs fc in the transaction of db, or in a new one when db
+// is not in a transaction.
+func inTransaction(db *gorm.DB, fc func()) {
+	stmt := db.Statement
+	if _, ok := stmt.ConnPool.(gorm.TxCommitter); ok {
+		fc()
+		return
+	}
+
+	var (
+		connPool gorm.ConnPool
+		err      error
+	)
+	switch beginner := stmt.ConnPool.(type) {
+	case gorm.TxBeginner:
+		connPool, err = beginner.BeginTx(stmt.Context, nil)
+	case gorm.ConnPoolBeginner:
+		connPool, err = beginner.BeginTx(stmt.Context, nil)
+	default:
+		fc()
+		return
+	}
+	if db.AddError(err) != nil {
+		return
+	}
+
+	committer, pool := connPool.(gorm.TxCommitter), stmt.ConnPool
+	stmt.ConnPool = connPool
+	defer func() {
+		stmt.ConnPool = pool
+		if db.Error != nil {
+			committer.Rollback()
+		} else {
+			db.AddError(committer.Commit())
+		}
+	}()
+	fc()
+}
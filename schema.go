@@ -0,0 +1,58 @@
+package iris
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultSchema is IRIS default schema, used when it is not configured
+// nor discovered
+const defaultSchema = "SQLUser"
+
+// schemaOf returns the schema of unqualified table names of db, from
+// DefaultSchemaSetting of the session or Config.DefaultSchema
+func (dialector Dialector) schemaOf(db *gorm.DB) string {
+	if schema, ok := db.Get(DefaultSchemaSetting); ok {
+		if schema, ok := schema.(string); ok && schema != "" {
+			return schema
+		}
+	}
+	if dialector.Config != nil && dialector.DefaultSchema != "" {
+		return dialector.DefaultSchema
+	}
+	return defaultSchema
+}
+
+// qualifiedTable returns the table of the statement qualified with schema,
+// when its name is not qualified and schema is not the default schema of
+// the connection, where IRIS looks for it anyway
+func (dialector Dialector) qualifiedTable(stmt *gorm.Statement, schema string) (clause.Expr, bool) {
+	serverSchema := defaultSchema
+	if dialector.Config != nil && dialector.serverSchema != "" {
+		serverSchema = dialector.serverSchema
+	}
+	if schema == "" || strings.EqualFold(schema, serverSchema) || stmt.Table == "" || strings.Contains(stmt.Table, ".") {
+		return clause.Expr{}, false
+	}
+	if stmt.TableExpr != nil && (len(stmt.TableExpr.Vars) > 0 || stmt.TableExpr.SQL != stmt.Quote(stmt.Table)) {
+		return clause.Expr{}, false
+	}
+	return clause.Expr{SQL: stmt.Quote(schema + "." + stmt.Table)}, true
+}
+
+// QualifyTable is registered before the callbacks of Create, Query, Update,
+// Delete and Row. The unqualified table of the statement is qualified with
+// Config.DefaultSchema, or DefaultSchemaSetting of the session, so rows are
+// written and read where the migrator creates and looks up tables. Joined
+// tables and raw SQL are left as they are.
+func QualifyTable(db *gorm.DB) {
+	dialector, ok := db.Dialector.(*Dialector)
+	if db.Error != nil || !ok {
+		return
+	}
+	if expr, ok := dialector.qualifiedTable(db.Statement, dialector.schemaOf(db)); ok {
+		db.Statement.TableExpr = &expr
+	}
+}
@@ -0,0 +1,274 @@
+package iris
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// maxStringLength is the longest string IRIS can hold, longer values have
+// to be stored in streams
+const maxStringLength = 3641144
+
+// streamChunkSize is the number of characters read from a stream at once
+const streamChunkSize = 32000
+
+// streamTypes maps types of type: tag to IRIS stream types
+var streamTypes = map[string]string{
+	"text":          "LONGVARCHAR",
+	"longtext":      "LONGVARCHAR",
+	"clob":          "LONGVARCHAR",
+	"longvarchar":   "LONGVARCHAR",
+	"blob":          "LONGVARBINARY",
+	"longblob":      "LONGVARBINARY",
+	"longvarbinary": "LONGVARBINARY",
+	"json":          "LONGVARCHAR",
+}
+
+// isKey reports whether the field is a key, is indexed or references
+// another table, which a stream can not be
+func isKey(field *schema.Field) bool {
+	if field.PrimaryKey || field.Unique {
+		return true
+	}
+	for _, key := range []string{"INDEX", "UNIQUEINDEX", "UNIQUE"} {
+		if _, ok := field.TagSettings[key]; ok {
+			return true
+		}
+	}
+	if field.Schema != nil {
+		for _, relation := range field.Schema.Relationships.Relations {
+			for _, reference := range relation.References {
+				if reference.ForeignKey == field || reference.PrimaryKey == field {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isStreamType reports whether the data type is a stream type
+func isStreamType(dataType string) bool {
+	switch strings.ToUpper(dataType) {
+	case "LONGVARCHAR", "LONGVARBINARY":
+		return true
+	}
+	return false
+}
+
+// isStream reports whether the field is stored in a stream
+func (dialector Dialector) isStream(field *schema.Field) bool {
+	if field == nil || field.DBName == "" {
+		return false
+	}
+	return isStreamType(dialector.DataTypeOf(field))
+}
+
+// ReadStreams is registered after gorm:query.
+// Stream columns of the model are selected with SUBSTRING up to
+// maxStringLength, the values as long are read again in full with
+// StreamReader, by the primary key of their row.
+func ReadStreams(db *gorm.DB) {
+	stmt := db.Statement
+	dialector, ok := db.Dialector.(*Dialector)
+	if db.Error != nil || db.DryRun || !ok || stmt.Schema == nil || db.RowsAffected == 0 {
+		return
+	}
+
+	var fields []*schema.Field
+	for _, field := range stmt.Schema.Fields {
+		if dialector.isStream(field) {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+
+	switch rv := stmt.ReflectValue; rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len() && db.Error == nil; i++ {
+			readLongStreams(db, fields, reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		readLongStreams(db, fields, rv)
+	}
+}
+
+// readLongStreams reads in full the stream fields of a row which may have
+// been cut by SUBSTRING
+func readLongStreams(db *gorm.DB, fields []*schema.Field, row reflect.Value) {
+	stmt := db.Statement
+	if row.Kind() != reflect.Struct || row.Type() != stmt.Schema.ModelType {
+		return
+	}
+	for _, field := range fields {
+		value := reflect.Indirect(field.ReflectValueOf(stmt.Context, row))
+		var length int
+		switch {
+		case value.Kind() == reflect.String:
+			length = utf8.RuneCountInString(value.String())
+		case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8:
+			length = value.Len()
+		default:
+			continue
+		}
+		if length < maxStringLength {
+			continue
+		}
+
+		if len(stmt.Schema.PrimaryFields) == 0 {
+			db.AddError(fmt.Errorf("iris: %s of %s is longer than %d characters, read it with StreamReader", field.DBName, stmt.Table, maxStringLength))
+			return
+		}
+		tx := db.Session(&gorm.Session{NewDB: true}).Table(stmt.Table)
+		for _, primaryField := range stmt.Schema.PrimaryFields {
+			primaryKey, _ := primaryField.ValueOf(stmt.Context, row)
+			tx = tx.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: primaryField.DBName}, Value: primaryKey})
+		}
+		data, err := io.ReadAll(newStreamReader(tx, field.DBName, field.DataType == schema.Bytes))
+		if db.AddError(err) != nil {
+			return
+		}
+		if value.Kind() == reflect.String {
+			value.SetString(string(data))
+		} else {
+			value.SetBytes(data)
+		}
+	}
+}
+
+// StreamReader returns a reader of a LONGVARCHAR or LONGVARBINARY column of
+// the row found by db, which is read in chunks without loading the whole
+// stream into memory.
+//
+//	r := iris.StreamReader(db.Model(&Document{}).Where("id = ?", id), "body")
+func StreamReader(db *gorm.DB, column string) io.Reader {
+	return newStreamReader(db, column, isBinaryColumn(db, column))
+}
+
+func newStreamReader(db *gorm.DB, column string, binary bool) *streamReader {
+	return &streamReader{db: db.Session(&gorm.Session{}), column: column, binary: binary}
+}
+
+// isBinaryColumn reports whether the column of the model of db is binary,
+// SUBSTRING counts bytes of binary streams and characters of others
+func isBinaryColumn(db *gorm.DB, column string) bool {
+	stmt := db.Statement
+	if stmt.Schema == nil && stmt.Model != nil {
+		if err := stmt.Parse(stmt.Model); err != nil {
+			return false
+		}
+	}
+	if stmt.Schema != nil {
+		if field := stmt.Schema.LookUpField(column); field != nil {
+			return field.DataType == schema.Bytes
+		}
+	}
+	return false
+}
+
+type streamReader struct {
+	db     *gorm.DB
+	column string
+	binary bool
+	offset int
+	buf    []byte
+	done   bool
+}
+
+func (r *streamReader) Read(p []byte) (n int, err error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		var chunk []byte
+		err = r.db.Select("SUBSTRING(?,?,?)", clause.Column{Name: r.column}, r.offset+1, streamChunkSize).
+			Limit(1).Row().Scan(&chunk)
+		if err != nil {
+			return 0, err
+		}
+		length := len(chunk)
+		if !r.binary {
+			length = utf8.RuneCount(chunk)
+		}
+		r.buf = chunk
+		r.offset += streamChunkSize
+		r.done = length < streamChunkSize
+	}
+	n = copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// StreamWriter returns a writer of a LONGVARCHAR or LONGVARBINARY column of
+// the rows found by db. What is written is sent in chunks, the first one
+// replaces the stream and the next ones are appended to it, so a document
+// is written without loading it whole into memory. Close writes the last
+// chunk, and has to be called even when nothing was written.
+//
+//	w := iris.StreamWriter(db.Model(&Document{}).Where("id = ?", id), "body")
+//	io.Copy(w, r)
+//	err := w.Close()
+func StreamWriter(db *gorm.DB, column string) io.WriteCloser {
+	return &streamWriter{db: db.Session(&gorm.Session{}), column: column, binary: isBinaryColumn(db, column)}
+}
+
+type streamWriter struct {
+	db      *gorm.DB
+	column  string
+	binary  bool
+	buf     []byte
+	written bool
+}
+
+func (w *streamWriter) Write(p []byte) (n int, err error) {
+	w.buf = append(w.buf, p...)
+	// a full chunk is kept until more is written, to look at the character
+	// after it, Close writes it otherwise
+	for len(w.buf) > streamChunkSize {
+		size := streamChunkSize
+		if !w.binary {
+			// a chunk does not end in the middle of a character
+			for size > 1 && !utf8.RuneStart(w.buf[size]) {
+				size--
+			}
+		}
+		if err = w.flush(w.buf[:size]); err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[size:]
+	}
+	return len(p), nil
+}
+
+func (w *streamWriter) Close() error {
+	if len(w.buf) == 0 && w.written {
+		return nil
+	}
+	err := w.flush(w.buf)
+	w.buf = nil
+	return err
+}
+
+func (w *streamWriter) flush(chunk []byte) error {
+	var value interface{} = string(chunk)
+	if w.binary {
+		value = append([]byte(nil), chunk...)
+	}
+	if w.written {
+		value = gorm.Expr("? || ?", clause.Column{Name: w.column}, value)
+	}
+	if err := w.db.UpdateColumn(w.column, value).Error; err != nil {
+		return err
+	}
+	w.written = true
+	return nil
+}
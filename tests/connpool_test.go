@@ -0,0 +1,60 @@
+package tests_test
+
+import (
+	"database/sql"
+	"testing"
+
+	iris "github.com/caretdev/gorm-iris"
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+func TestOpenWithConn(t *testing.T) {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql.DB, got error %v", err)
+	}
+
+	db, err := gorm.Open(iris.New(iris.Config{Conn: sqlDB}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open with existing connection, got error %v", err)
+	}
+
+	if db.ConnPool != sqlDB {
+		t.Errorf("supplied connection pool should be used as is")
+	}
+
+	var count int64
+	if err := db.Model(&User{}).Count(&count).Error; err != nil {
+		t.Errorf("failed to query with existing connection, got error %v", err)
+	}
+}
+
+func TestOpenDB(t *testing.T) {
+	sqlDB, err := iris.OpenDB(iris.DefaultDriverName, connectionString)
+	if err != nil {
+		t.Fatalf("failed to open sql.DB, got error %v", err)
+	}
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(iris.New(iris.Config{Conn: sqlDB}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open with existing connection, got error %v", err)
+	}
+
+	tx := db.Begin(&sql.TxOptions{ReadOnly: true})
+	if err := tx.Error; err != nil {
+		t.Fatalf("read-only transaction should begin, got %v", err)
+	}
+	defer tx.Rollback()
+	if err := tx.Exec("DELETE FROM users WHERE 1 = 0").Error; err == nil {
+		t.Errorf("read-only transaction should not write")
+	}
+}
+
+func TestOpenWithUnknownDriver(t *testing.T) {
+	_, err := gorm.Open(iris.New(iris.Config{DriverName: "unknown", DSN: connectionString}), &gorm.Config{})
+	if err == nil {
+		t.Errorf("should return error for unknown driver")
+	}
+}
@@ -0,0 +1,153 @@
+package tests_test
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	. "gorm.io/gorm/utils/tests"
+)
+
+func TestCreateSlice(t *testing.T) {
+	users := []User{
+		*GetUser("create_slice_1", Config{}),
+		*GetUser("create_slice_2", Config{}),
+		*GetUser("create_slice_3", Config{}),
+	}
+
+	if results := DB.Create(&users); results.Error != nil {
+		t.Fatalf("errors happened on create slice: %v", results.Error)
+	} else if results.RowsAffected != int64(len(users)) {
+		t.Fatalf("rows affected expects: %v, got %v", len(users), results.RowsAffected)
+	}
+
+	for _, user := range users {
+		if user.ID == 0 {
+			t.Fatalf("failed to fill user's ID, got %v", user.ID)
+		} else {
+			var newUser User
+			if err := DB.Where("id = ?", user.ID).First(&newUser).Error; err != nil {
+				t.Fatalf("errors happened when query: %v", err)
+			} else {
+				CheckUser(t, newUser, user)
+			}
+		}
+	}
+}
+
+func TestCreateInBatches(t *testing.T) {
+	users := []User{
+		*GetUser("create_in_batches_1", Config{}),
+		*GetUser("create_in_batches_2", Config{}),
+		*GetUser("create_in_batches_3", Config{}),
+		*GetUser("create_in_batches_4", Config{}),
+		*GetUser("create_in_batches_5", Config{}),
+		*GetUser("create_in_batches_6", Config{}),
+	}
+
+	result := DB.CreateInBatches(&users, 4)
+	if result.RowsAffected != int64(len(users)) {
+		t.Errorf("affected rows should be %v, but got %v", len(users), result.RowsAffected)
+	}
+
+	for _, user := range users {
+		if user.ID == 0 {
+			t.Fatalf("failed to fill user's ID, got %v", user.ID)
+		} else {
+			var newUser User
+			if err := DB.Where("id = ?", user.ID).First(&newUser).Error; err != nil {
+				t.Fatalf("errors happened when query: %v", err)
+			} else {
+				CheckUser(t, newUser, user)
+			}
+		}
+	}
+}
+
+func TestCreateSliceOfMap(t *testing.T) {
+	values := []map[string]interface{}{
+		{"name": "create_slice_of_map_1", "age": 18},
+		{"name": "create_slice_of_map_2", "age": 19},
+	}
+
+	if err := DB.Model(&User{}).Create(&values).Error; err != nil {
+		t.Fatalf("failed to create slice of map, got error: %v", err)
+	}
+
+	for _, value := range values {
+		var user User
+		if err := DB.Where("name = ?", value["name"]).First(&user).Error; err != nil {
+			t.Fatalf("failed to find created user, got error: %v", err)
+		} else if value["id"] == nil {
+			t.Errorf("failed to fill id of created map, got %v", value)
+		} else if id, ok := value["id"].(int64); !ok || uint(id) != user.ID {
+			t.Errorf("created map id should be %v, but got %v", user.ID, value["id"])
+		}
+	}
+}
+
+func TestCreateSliceDryRun(t *testing.T) {
+	users := []User{*GetUser("create_dry_run_1", Config{}), *GetUser("create_dry_run_2", Config{})}
+	stmt := DB.Session(&gorm.Session{DryRun: true}).Create(&users).Statement
+
+	if got := len(stmt.Vars); got == 0 || got%len(users) != 0 {
+		t.Errorf("vars of every row should be collected, got %v", stmt.Vars)
+	}
+
+	// every row is inserted with its own INSERT, IRIS has no multi-row VALUES
+	statements := strings.Split(stmt.SQL.String(), "; ")
+	if len(statements) != len(users) {
+		t.Fatalf("dry run should have an INSERT for every row, got %v", stmt.SQL.String())
+	}
+	for _, statement := range statements {
+		if !strings.HasPrefix(statement, `INSERT INTO "users" (`) || strings.Contains(statement, "),(") {
+			t.Errorf("dry run should have an INSERT of a single row, got %v", statement)
+		}
+	}
+	if statements[0] != statements[1] {
+		t.Errorf("rows should be inserted with the same INSERT, got %v", stmt.SQL.String())
+	}
+}
+
+func TestCreateDuplicatedPrimaryKey(t *testing.T) {
+	user := *GetUser("create_duplicated_key", Config{})
+	if err := DB.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got error: %v", err)
+	}
+
+	duplicated := *GetUser("create_duplicated_key_2", Config{})
+	duplicated.ID = user.ID
+	if err := DB.Create(&duplicated).Error; err == nil {
+		t.Fatalf("should fail to create user with duplicated primary key")
+	}
+
+	if err := DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&duplicated).Error; err != nil {
+		t.Fatalf("failed to upsert user, got error: %v", err)
+	}
+
+	var result User
+	if err := DB.First(&result, user.ID).Error; err != nil {
+		t.Fatalf("failed to find user, got error: %v", err)
+	} else if result.Name != duplicated.Name {
+		t.Errorf("user should be updated on conflict, expects name %v, got %v", duplicated.Name, result.Name)
+	}
+}
+
+func TestCreateInsertSQL(t *testing.T) {
+	user := *GetUser("create_insert_sql", Config{})
+
+	sql := DB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Create(&user)
+	})
+	if !strings.HasPrefix(sql, "INSERT INTO ") {
+		t.Errorf("create should use plain INSERT, got %v", sql)
+	}
+
+	sql = DB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&user)
+	})
+	if !strings.HasPrefix(sql, "INSERT OR UPDATE ") {
+		t.Errorf("upsert should use INSERT OR UPDATE, got %v", sql)
+	}
+}
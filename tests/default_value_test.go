@@ -1,6 +1,7 @@
 package tests_test
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -57,3 +58,58 @@ func TestDefaultValue(t *testing.T) {
 		t.Fatalf("Failed to create data with default value, got: %+v", harumph2)
 	}
 }
+
+func TestDefaultValueFunctions(t *testing.T) {
+	type DefaultFunction struct {
+		ID       uint
+		Name     string    `gorm:"size:20;default:'new'"`
+		Horolog  string    `gorm:"size:50;default:$HOROLOG"`
+		Enabled  bool      `gorm:"default:false"`
+		Created  time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+		Modified time.Time `gorm:"default:{fn NOW()}"`
+	}
+
+	DB.Migrator().DropTable(&DefaultFunction{})
+	if err := DB.AutoMigrate(&DefaultFunction{}); err != nil {
+		t.Fatalf("Failed to migrate with default functions, got error: %v", err)
+	}
+
+	if err := DB.Exec(`INSERT INTO "default_functions" ("enabled") VALUES (1)`).Error; err != nil {
+		t.Fatalf("Failed to insert, got error: %v", err)
+	}
+	var result DefaultFunction
+	if err := DB.First(&result).Error; err != nil {
+		t.Fatalf("Failed to find created data, got error: %v", err)
+	} else if result.Name != "new" || result.Horolog == "" || result.Horolog == "$HOROLOG" || result.Created.IsZero() || result.Modified.IsZero() {
+		t.Fatalf("Failed to find created data with default functions, got %+v", result)
+	}
+
+	alters := collectSQL(t, DB)
+
+	if err := DB.AutoMigrate(&DefaultFunction{}); err != nil {
+		t.Fatalf("Failed to migrate again, got error: %v", err)
+	} else if len(*alters) != 0 {
+		t.Errorf("migrating unchanged defaults should not alter table, got %v", *alters)
+	}
+}
+
+func TestDefaultValueDDL(t *testing.T) {
+	type DefaultLiteral struct {
+		ID      uint
+		Enabled bool    `gorm:"default:true"`
+		Hidden  bool    `gorm:"default:false"`
+		Age     int     `gorm:"default:18"`
+		Rate    float64 `gorm:"default:1.5"`
+	}
+
+	stmt := &gorm.Statement{DB: DB}
+	if err := stmt.Parse(&DefaultLiteral{}); err != nil {
+		t.Fatalf("failed to parse model, got %v", err)
+	}
+	expected := map[string]string{"enabled": "DEFAULT 1", "hidden": "DEFAULT 0", "age": "DEFAULT 18", "rate": "DEFAULT 1.5"}
+	for name, defaultValue := range expected {
+		if sql := DB.Migrator().FullDataTypeOf(stmt.Schema.LookUpField(name)).SQL; !strings.HasSuffix(sql, defaultValue) {
+			t.Errorf("column %v should have %v, got %v", name, defaultValue, sql)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package tests_test
+
+import (
+	"errors"
+	"testing"
+
+	iris "github.com/caretdev/gorm-iris"
+	"gorm.io/gorm"
+)
+
+func TestTranslateDuplicatedKey(t *testing.T) {
+	type City struct {
+		gorm.Model
+		Name string `gorm:"size:100;unique"`
+	}
+
+	db, err := OpenTestConnection(&gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+
+	db.Migrator().DropTable(&City{})
+	if err = db.AutoMigrate(&City{}); err != nil {
+		t.Fatalf("failed to migrate cities, got error %v", err)
+	}
+
+	if err = db.Create(&City{Name: "Kabul"}).Error; err != nil {
+		t.Fatalf("failed to create city, got error %v", err)
+	}
+
+	err = db.Create(&City{Name: "Kabul"}).Error
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Fatalf("expected err: %v, got %v", gorm.ErrDuplicatedKey, err)
+	}
+
+	var irisErr *iris.Error
+	if !errors.As(err, &irisErr) {
+		t.Fatalf("expected *iris.Error, got %T", err)
+	} else if irisErr.SQLCode != -119 {
+		t.Errorf("expected SQLCODE -119, got %v", irisErr.SQLCode)
+	}
+}
+
+func TestTranslateForeignKeyViolated(t *testing.T) {
+	type Country struct {
+		gorm.Model
+		Name string
+	}
+	type Town struct {
+		gorm.Model
+		Name      string
+		CountryID uint
+		Country   Country
+	}
+
+	db, err := OpenTestConnection(&gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+
+	db.Migrator().DropTable(&Town{}, &Country{})
+	if err = db.AutoMigrate(&Country{}, &Town{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	err = db.Omit("Country").Create(&Town{Name: "Nowhere", CountryID: 100500}).Error
+	if !errors.Is(err, gorm.ErrForeignKeyViolated) {
+		t.Fatalf("expected err: %v, got %v", gorm.ErrForeignKeyViolated, err)
+	}
+}
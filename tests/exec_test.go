@@ -0,0 +1,52 @@
+package tests_test
+
+import (
+	"strings"
+	"testing"
+
+	. "gorm.io/gorm/utils/tests"
+)
+
+func TestExecMultipleStatements(t *testing.T) {
+	user := *GetUser("exec_statements", Config{})
+	DB.Create(&user)
+
+	result := DB.Exec(
+		"UPDATE users SET age = ? WHERE id = ?; UPDATE users SET name = 'exec;statements' WHERE id = ?",
+		10, user.ID, user.ID,
+	)
+	if result.Error != nil {
+		t.Fatalf("failed to exec statements, got %v", result.Error)
+	}
+	if result.RowsAffected != 2 {
+		t.Errorf("rows affected should be 2, got %v", result.RowsAffected)
+	}
+
+	var got User
+	DB.First(&got, user.ID)
+	if got.Age != 10 || got.Name != "exec;statements" {
+		t.Errorf("statements should be run, got %+v", got)
+	}
+}
+
+func TestExecMultipleStatementsError(t *testing.T) {
+	user := *GetUser("exec_statements_error", Config{})
+	DB.Create(&user)
+
+	err := DB.Exec(
+		"UPDATE users SET age = 20 WHERE id = ?; UPDATE no_such_table SET age = 1",
+		user.ID,
+	).Error
+	if err == nil {
+		t.Fatalf("failing statement should return error")
+	}
+	if !strings.Contains(err.Error(), "statement 2 of 2") || !strings.Contains(err.Error(), "no_such_table") {
+		t.Errorf("error should point at the failing statement, got %v", err)
+	}
+
+	var got User
+	DB.First(&got, user.ID)
+	if got.Age == 20 {
+		t.Errorf("statements should be rolled back")
+	}
+}
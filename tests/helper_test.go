@@ -292,3 +292,18 @@ func db(unscoped bool) *gorm.DB {
 		return DB
 	}
 }
+
+// collectSQL collects the SQL of the raw statements, as the migrator runs
+// its DDL, until the end of the test. It changes the global callbacks of
+// db, which a Session shares, so the statements of every session of the
+// connection are collected, not only the ones of db.
+func collectSQL(t *testing.T, db *gorm.DB) *[]string {
+	var statements []string
+	db.Callback().Raw().After("gorm:raw").Register("collect_sql", func(tx *gorm.DB) {
+		statements = append(statements, tx.Statement.SQL.String())
+	})
+	t.Cleanup(func() {
+		db.Callback().Raw().Remove("collect_sql")
+	})
+	return &statements
+}
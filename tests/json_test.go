@@ -0,0 +1,113 @@
+package tests_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	iris "github.com/caretdev/gorm-iris"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Product struct {
+	ID    uint
+	Name  string `gorm:"size:100"`
+	Attrs iris.JSON
+}
+
+func TestJSON(t *testing.T) {
+	DB.Migrator().DropTable(&Product{})
+	if err := DB.AutoMigrate(&Product{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	products := []Product{
+		{Name: "apple", Attrs: iris.JSON(`{"color":"red","size":{"width":3},"fresh":true}`)},
+		{Name: "banana", Attrs: iris.JSON(`{"color":"yellow"}`)},
+		{Name: "unknown"},
+	}
+	if err := DB.Create(&products).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	var result Product
+	if err := DB.First(&result, products[0].ID).Error; err != nil {
+		t.Fatalf("failed to find, got %v", err)
+	}
+	var attrs map[string]interface{}
+	if err := json.Unmarshal(result.Attrs, &attrs); err != nil || attrs["color"] != "red" {
+		t.Errorf("json should be read back, got %s, %v", result.Attrs, err)
+	}
+
+	var names []string
+	DB.Model(&Product{}).Where(iris.JSONQuery("attrs").HasKey("color")).Order("name").Pluck("name", &names)
+	if strings.Join(names, ",") != "apple,banana" {
+		t.Errorf("products with color should be apple and banana, got %v", names)
+	}
+
+	names = nil
+	DB.Model(&Product{}).Where(iris.JSONQuery("attrs").HasKey("size", "width")).Pluck("name", &names)
+	if strings.Join(names, ",") != "apple" {
+		t.Errorf("products with size width should be apple, got %v", names)
+	}
+
+	names = nil
+	DB.Model(&Product{}).Where(iris.JSONQuery("attrs").Equals("yellow", "color")).Pluck("name", &names)
+	if strings.Join(names, ",") != "banana" {
+		t.Errorf("yellow products should be banana, got %v", names)
+	}
+
+	names = nil
+	DB.Model(&Product{}).Where(iris.JSONQuery("attrs").Equals(true, "fresh")).Pluck("name", &names)
+	if strings.Join(names, ",") != "apple" {
+		t.Errorf("fresh products should be apple, got %v", names)
+	}
+
+	special := Product{Name: "special", Attrs: iris.JSON(`{"a.b":1,"with space":{"say \"hi\"":"yes"}}`)}
+	if err := DB.Create(&special).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+	names = nil
+	DB.Model(&Product{}).Where(iris.JSONQuery("attrs").HasKey("a.b")).Pluck("name", &names)
+	if strings.Join(names, ",") != "special" {
+		t.Errorf("products with key a.b should be special, got %v", names)
+	}
+	names = nil
+	DB.Model(&Product{}).Where(iris.JSONQuery("attrs").Equals("yes", "with space", `say "hi"`)).Pluck("name", &names)
+	if strings.Join(names, ",") != "special" {
+		t.Errorf("products with quoted keys should be special, got %v", names)
+	}
+
+	var objects []string
+	if err := DB.Model(&Product{}).Select("?", iris.JSONObject("name", clause.Column{Name: "name"}, "tags", iris.JSONArray("a", 1))).
+		Where("name = ?", "banana").Scan(&objects).Error; err != nil {
+		t.Fatalf("failed to select JSON_OBJECT, got %v", err)
+	} else if len(objects) != 1 || objects[0] != `{"name":"banana","tags":["a",1]}` {
+		t.Errorf("JSON_OBJECT should be built, got %v", objects)
+	}
+
+	var values []int
+	if err := DB.Table("?", iris.JSONTable(`[{"a":1},{"a":2}]`, "$", iris.JSONTableColumn{Name: "a", Type: "INTEGER", Path: "$.a"})).
+		Select("a").Scan(&values).Error; err != nil {
+		t.Fatalf("failed to select from JSON_TABLE, got %v", err)
+	} else if len(values) != 2 || values[0] != 1 || values[1] != 2 {
+		t.Errorf("JSON_TABLE should return 1, 2, got %v", values)
+	}
+}
+
+func TestJSONQuerySQL(t *testing.T) {
+	stmt := DB.Session(&gorm.Session{DryRun: true}).Where(iris.JSONQuery("attrs").Equals("it's", "a", "b")).Find(&[]Product{}).Statement
+	expected := `WHERE EXISTS (SELECT 1 FROM JSON_TABLE("products"."attrs",'$' COLUMNS ("value" VARCHAR(3641144) PATH '$."a"."b"')) WHERE "value" = ?)`
+	if sql := stmt.SQL.String(); !strings.Contains(sql, expected) {
+		t.Errorf("JSON query should be %v, got %v", expected, sql)
+	}
+}
+
+func TestJSONQueryQuotedKeys(t *testing.T) {
+	stmt := DB.Session(&gorm.Session{DryRun: true}).Where(iris.JSONQuery("attrs").HasKey("a.b", `say "hi"`, "it's")).Find(&[]Product{}).Statement
+	expected := `PATH '$."a.b"."say \"hi\""."it''s"'`
+	if sql := stmt.SQL.String(); !strings.Contains(sql, expected) {
+		t.Errorf("keys of JSON path should be quoted as %v, got %v", expected, sql)
+	}
+}
@@ -0,0 +1,89 @@
+package tests_test
+
+import (
+	"strings"
+	"testing"
+
+	iris "github.com/caretdev/gorm-iris"
+	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
+)
+
+// openWithServerVersion opens a connection which takes the server for the
+// given release, closed at the end of the test
+func openWithServerVersion(t *testing.T, version string) *gorm.DB {
+	db, err := gorm.Open(iris.New(iris.Config{DSN: connectionString, ServerVersion: version}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		t.Cleanup(func() { sqlDB.Close() })
+	}
+	return db
+}
+
+func TestLimitOffset(t *testing.T) {
+	users := []User{
+		*GetUser("limit_offset_1", Config{}),
+		*GetUser("limit_offset_2", Config{}),
+		*GetUser("limit_offset_3", Config{}),
+		*GetUser("limit_offset_4", Config{}),
+		*GetUser("limit_offset_5", Config{}),
+	}
+	DB.Create(&users)
+
+	for _, version := range []string{"2022.1", "2025.1"} {
+		t.Run(version, func(t *testing.T) {
+			db := openWithServerVersion(t, version).Where("name LIKE ?", "limit_offset_%").Order("name").Session(&gorm.Session{})
+
+			var result []User
+			if err := db.Limit(2).Find(&result).Error; err != nil {
+				t.Fatalf("failed to query with limit, got error %v", err)
+			} else if len(result) != 2 || result[0].Name != "limit_offset_1" {
+				t.Errorf("limit 2 expects 2 users from first, got %+v", result)
+			}
+
+			if err := db.Limit(2).Offset(2).Find(&result).Error; err != nil {
+				t.Fatalf("failed to query with limit and offset, got error %v", err)
+			} else if len(result) != 2 || result[0].Name != "limit_offset_3" || result[1].Name != "limit_offset_4" {
+				t.Errorf("limit 2 offset 2 expects users 3 and 4, got %+v", result)
+			}
+
+			if err := db.Offset(3).Find(&result).Error; err != nil {
+				t.Fatalf("failed to query with offset, got error %v", err)
+			} else if len(result) != 2 || result[0].Name != "limit_offset_4" {
+				t.Errorf("offset 3 expects users 4 and 5, got %+v", result)
+			}
+
+			var user User
+			if err := db.First(&user).Error; err != nil {
+				t.Fatalf("failed to query first, got error %v", err)
+			}
+		})
+	}
+}
+
+func TestLimitWithTop(t *testing.T) {
+	db := openWithServerVersion(t, "2022.1")
+
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&User{}).Limit(10).Find(&[]User{})
+	})
+	if !strings.HasPrefix(sql, "SELECT TOP 10 ") || strings.Contains(sql, "LIMIT") {
+		t.Errorf("limit should be done with TOP, got %v", sql)
+	}
+
+	sql = db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&User{}).Limit(10).Offset(20).Find(&[]User{})
+	})
+	if !strings.HasPrefix(sql, "SELECT * FROM (SELECT TOP ALL ") || !strings.HasSuffix(sql, "WHERE %VID > 20 AND %VID <= 30") {
+		t.Errorf("offset should be done with %%VID, got %v", sql)
+	}
+
+	sql = openWithServerVersion(t, "2025.1").ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&User{}).Limit(10).Offset(20).Find(&[]User{})
+	})
+	if !strings.Contains(sql, "LIMIT 10 OFFSET 20") {
+		t.Errorf("limit should be used when supported, got %v", sql)
+	}
+}
@@ -0,0 +1,173 @@
+package tests_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	iris "github.com/caretdev/gorm-iris"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	. "gorm.io/gorm/utils/tests"
+)
+
+func TestLocking(t *testing.T) {
+	user := *GetUser("locking", Config{})
+	DB.Create(&user)
+
+	sql := DB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).Find(&[]User{})
+	})
+	if strings.Contains(sql, "FOR UPDATE") {
+		t.Errorf("IRIS does not support FOR UPDATE, got %v", sql)
+	}
+
+	db, err := OpenTestConnection(&gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		t.Cleanup(func() { sqlDB.Close() })
+	}
+
+	tx := DB.Begin()
+	defer tx.Rollback()
+
+	var locked User
+	if err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).Where("name = ?", user.Name).First(&locked).Error; err != nil {
+		t.Fatalf("failed to lock user, got error %v", err)
+	} else if locked.ID != user.ID {
+		t.Fatalf("locked user expects %v, got %v", user.ID, locked.ID)
+	}
+
+	err = db.Transaction(func(tx2 *gorm.DB) error {
+		return tx2.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsNoWait}).
+			Where("name = ?", user.Name).First(&User{}).Error
+	})
+	if !errors.Is(err, iris.ErrLockTimeout) {
+		t.Errorf("locked row should not be available, expects %v, got %v", iris.ErrLockTimeout, err)
+	}
+
+	err = db.Transaction(func(tx2 *gorm.DB) error {
+		return tx2.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked}).
+			Find(&[]User{}).Error
+	})
+	if err == nil {
+		t.Errorf("SKIP LOCKED should not be supported")
+	}
+}
+
+func TestLockingLimit(t *testing.T) {
+	users := []User{*GetUser("locking_limit", Config{}), *GetUser("locking_limit", Config{})}
+	DB.Create(&users)
+
+	db, err := OpenTestConnection(&gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		t.Cleanup(func() { sqlDB.Close() })
+	}
+
+	tx := DB.Begin()
+	defer tx.Rollback()
+
+	var locked User
+	if err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).Where("name = ?", "locking_limit").Order("id").Limit(1).Find(&locked).Error; err != nil {
+		t.Fatalf("failed to lock user, got error %v", err)
+	} else if locked.ID != users[0].ID {
+		t.Fatalf("locked user expects %v, got %v", users[0].ID, locked.ID)
+	}
+
+	err = db.Transaction(func(tx2 *gorm.DB) error {
+		return tx2.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsNoWait}).
+			First(&User{}, users[1].ID).Error
+	})
+	if err != nil {
+		t.Errorf("rows out of the limit should not be locked, got %v", err)
+	}
+
+	err = db.Transaction(func(tx2 *gorm.DB) error {
+		return tx2.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsNoWait}).
+			First(&User{}, users[0].ID).Error
+	})
+	if !errors.Is(err, iris.ErrLockTimeout) {
+		t.Errorf("locked row should not be available, expects %v, got %v", iris.ErrLockTimeout, err)
+	}
+}
+
+func TestLockingShare(t *testing.T) {
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(clause.Locking{Strength: clause.LockingStrengthShare}).Find(&[]User{}).Error
+	})
+	if err == nil {
+		t.Errorf("FOR SHARE should not be supported")
+	}
+}
+
+// recordingTx is the connection pool of a transaction, which records the
+// statements it executes
+type recordingTx struct {
+	gorm.ConnPool
+	statements []string
+}
+
+func (r *recordingTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	r.statements = append(r.statements, query)
+	return r.ConnPool.ExecContext(ctx, query, args...)
+}
+
+func (r *recordingTx) Commit() error {
+	return r.ConnPool.(gorm.TxCommitter).Commit()
+}
+
+func (r *recordingTx) Rollback() error {
+	return r.ConnPool.(gorm.TxCommitter).Rollback()
+}
+
+func TestLockingSQL(t *testing.T) {
+	tx := DB.Begin()
+	defer tx.Rollback()
+	recorder := &recordingTx{ConnPool: tx.Statement.ConnPool}
+	tx.Statement.ConnPool = recorder
+
+	tests := []struct {
+		name  string
+		query func(*gorm.DB) *gorm.DB
+		sql   string
+	}{
+		{
+			name:  "all",
+			query: func(tx *gorm.DB) *gorm.DB { return tx },
+			sql:   `WHERE %ID IN (SELECT "users".%ID FROM "users" WHERE name = ?`,
+		},
+		{
+			name:  "limit",
+			query: func(tx *gorm.DB) *gorm.DB { return tx.Order("id").Limit(2) },
+			sql:   `WHERE %ID IN (SELECT TOP 2 "users".%ID FROM "users" WHERE name = ? AND "users"."deleted_at" IS NULL ORDER BY id)`,
+		},
+		{
+			name:  "offset",
+			query: func(tx *gorm.DB) *gorm.DB { return tx.Order("id").Limit(2).Offset(1) },
+			sql:   `WHERE %ID IN (SELECT lock_id FROM (SELECT TOP ALL "users".%ID AS lock_id FROM "users" WHERE name = ? AND "users"."deleted_at" IS NULL ORDER BY id) WHERE %VID > 1 AND %VID <= 3)`,
+		},
+	}
+	for _, test := range tests {
+		recorder.statements = nil
+		if err := test.query(tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate})).Where("name = ?", "locking_sql").Find(&[]User{}).Error; err != nil {
+			t.Fatalf("failed to lock %v, got %v", test.name, err)
+		}
+
+		var lock string
+		for _, statement := range recorder.statements {
+			if strings.HasPrefix(statement, "UPDATE ") {
+				lock = statement
+			}
+		}
+		if !strings.Contains(lock, test.sql) {
+			t.Errorf("lock of %v should contain %q, got %q", test.name, test.sql, lock)
+		}
+	}
+}
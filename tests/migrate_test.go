@@ -0,0 +1,453 @@
+package tests_test
+
+import (
+	"strings"
+	"testing"
+
+	iris "github.com/caretdev/gorm-iris"
+	"gorm.io/gorm"
+)
+
+func TestMigrateColumnTypes(t *testing.T) {
+	type ColumnStruct struct {
+		ID    uint    `gorm:"primaryKey;autoIncrement"`
+		Name  string  `gorm:"size:100;not null"`
+		Code  string  `gorm:"size:20;unique"`
+		Price float64 `gorm:"precision:10;scale:2"`
+		Note  string  `gorm:"size:50"`
+	}
+
+	DB.Migrator().DropTable(&ColumnStruct{})
+	if err := DB.AutoMigrate(&ColumnStruct{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	columnTypes, err := DB.Migrator().ColumnTypes(&ColumnStruct{})
+	if err != nil {
+		t.Fatalf("failed to get column types, got error: %v", err)
+	}
+
+	found := map[string]gorm.ColumnType{}
+	for _, columnType := range columnTypes {
+		found[columnType.Name()] = columnType
+	}
+
+	if columnType, ok := found["id"]; !ok {
+		t.Errorf("column id not found")
+	} else {
+		if pk, ok := columnType.PrimaryKey(); !ok || !pk {
+			t.Errorf("column id should be primary key")
+		}
+		if autoIncrement, ok := columnType.AutoIncrement(); !ok || !autoIncrement {
+			t.Errorf("column id should be auto increment")
+		}
+	}
+
+	if columnType, ok := found["name"]; !ok {
+		t.Errorf("column name not found")
+	} else {
+		if length, ok := columnType.Length(); !ok || length != 100 {
+			t.Errorf("column name length should be 100, got %v", length)
+		}
+		if nullable, ok := columnType.Nullable(); !ok || nullable {
+			t.Errorf("column name should not be nullable")
+		}
+	}
+
+	if columnType, ok := found["code"]; !ok {
+		t.Errorf("column code not found")
+	} else if unique, ok := columnType.Unique(); !ok || !unique {
+		t.Errorf("column code should be unique")
+	}
+
+	if columnType, ok := found["price"]; !ok {
+		t.Errorf("column price not found")
+	} else if precision, scale, ok := columnType.DecimalSize(); !ok || precision != 10 || scale != 2 {
+		t.Errorf("column price should be numeric(10,2), got %v,%v", precision, scale)
+	}
+
+	if columnType, ok := found["note"]; !ok {
+		t.Errorf("column note not found")
+	} else if nullable, ok := columnType.Nullable(); !ok || !nullable {
+		t.Errorf("column note should be nullable")
+	}
+
+	alters := collectSQL(t, DB)
+
+	if err := DB.AutoMigrate(&ColumnStruct{}); err != nil {
+		t.Fatalf("failed to migrate again, got %v", err)
+	} else if len(*alters) != 0 {
+		t.Errorf("migrating unchanged model should not alter table, got %v", *alters)
+	}
+}
+
+func TestMigrateIndexes(t *testing.T) {
+	type IndexStruct struct {
+		ID    uint
+		Name  string `gorm:"size:100;index:idx_index_structs_name_code,priority:1"`
+		Code  string `gorm:"size:20;index:idx_index_structs_name_code,priority:2"`
+		Email string `gorm:"size:100;uniqueIndex"`
+		Flag  bool   `gorm:"index:idx_index_structs_flag,class:BITMAP"`
+	}
+
+	DB.Migrator().DropTable(&IndexStruct{})
+	if err := DB.AutoMigrate(&IndexStruct{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	for _, name := range []string{"idx_index_structs_name_code", "idx_index_structs_email", "idx_index_structs_flag"} {
+		if !DB.Migrator().HasIndex(&IndexStruct{}, name) {
+			t.Errorf("index %v should exist", name)
+		}
+	}
+	if DB.Migrator().HasIndex(&IndexStruct{}, "idx_index_structs_missing") {
+		t.Errorf("index idx_index_structs_missing should not exist")
+	}
+
+	indexes, err := DB.Migrator().GetIndexes(&IndexStruct{})
+	if err != nil {
+		t.Fatalf("failed to get indexes, got error: %v", err)
+	}
+
+	found := map[string]gorm.Index{}
+	for _, idx := range indexes {
+		found[idx.Name()] = idx
+	}
+
+	if idx, ok := found["idx_index_structs_name_code"]; !ok {
+		t.Errorf("index idx_index_structs_name_code not found")
+	} else {
+		if columns := idx.Columns(); len(columns) != 2 || columns[0] != "name" || columns[1] != "code" {
+			t.Errorf("index idx_index_structs_name_code should have columns name, code, got %v", columns)
+		}
+		if unique, ok := idx.Unique(); !ok || unique {
+			t.Errorf("index idx_index_structs_name_code should not be unique")
+		}
+	}
+
+	if idx, ok := found["idx_index_structs_email"]; !ok {
+		t.Errorf("index idx_index_structs_email not found")
+	} else if unique, ok := idx.Unique(); !ok || !unique {
+		t.Errorf("index idx_index_structs_email should be unique")
+	}
+
+	if idx, ok := found["idx_index_structs_flag"]; !ok {
+		t.Errorf("index idx_index_structs_flag not found")
+	} else if irisIdx, ok := idx.(iris.Index); !ok || irisIdx.Type() != "bitmap" {
+		t.Errorf("index idx_index_structs_flag should be bitmap, got %#v", idx)
+	}
+
+	creates := collectSQL(t, DB)
+
+	if err := DB.AutoMigrate(&IndexStruct{}); err != nil {
+		t.Fatalf("failed to migrate again, got %v", err)
+	} else if len(*creates) != 0 {
+		t.Errorf("migrating unchanged model should not recreate indexes, got %v", *creates)
+	}
+}
+
+func TestMigrateDefaultSchema(t *testing.T) {
+	type SchemaStruct struct {
+		ID   uint
+		Name string
+	}
+
+	if dialector, ok := DB.Dialector.(*iris.Dialector); !ok || dialector.DefaultSchema != "SQLUser" {
+		t.Errorf("default schema should be discovered as SQLUser, got %#v", DB.Dialector)
+	}
+
+	DB.Exec(`DROP TABLE IF EXISTS "GormOther"."schema_structs"`)
+	if err := DB.Exec(`CREATE TABLE "GormOther"."schema_structs" ("id" INTEGER, "name" VARCHAR(100))`).Error; err != nil {
+		t.Fatalf("failed to create table, got %v", err)
+	}
+	defer DB.Exec(`DROP TABLE IF EXISTS "GormOther"."schema_structs"`)
+	DB.Migrator().DropTable(&SchemaStruct{})
+
+	if DB.Migrator().HasTable(&SchemaStruct{}) {
+		t.Errorf("table schema_structs should not exist in the default schema")
+	}
+
+	other := DB.Set(iris.DefaultSchemaSetting, "GormOther")
+	if !other.Migrator().HasTable(&SchemaStruct{}) {
+		t.Errorf("table schema_structs should exist in GormOther")
+	}
+	if !other.Migrator().HasColumn(&SchemaStruct{}, "Name") {
+		t.Errorf("column name should exist in GormOther.schema_structs")
+	}
+
+	tables, err := other.Migrator().GetTables()
+	if err != nil {
+		t.Fatalf("failed to get tables, got %v", err)
+	}
+	if len(tables) != 1 || tables[0] != "schema_structs" {
+		t.Errorf("GormOther should have table schema_structs only, got %v", tables)
+	}
+}
+
+func TestMigrateConfiguredDefaultSchema(t *testing.T) {
+	type SchemaStruct struct {
+		ID   uint
+		Name string `gorm:"index"`
+	}
+
+	db, err := gorm.Open(iris.New(iris.Config{DSN: connectionString, DefaultSchema: "GormOther"}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		t.Cleanup(func() { sqlDB.Close() })
+	}
+
+	DB.Exec(`DROP TABLE IF EXISTS "GormOther"."schema_structs"`)
+	DB.Migrator().DropTable(&SchemaStruct{})
+	if err := db.AutoMigrate(&SchemaStruct{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+	defer db.Migrator().DropTable(&SchemaStruct{})
+
+	if !db.Migrator().HasTable(&SchemaStruct{}) || !db.Migrator().HasIndex(&SchemaStruct{}, "Name") {
+		t.Errorf("table schema_structs should be created in GormOther with its index")
+	}
+	if DB.Migrator().HasTable(&SchemaStruct{}) {
+		t.Errorf("table schema_structs should not be created in the default schema")
+	}
+
+	if err := db.Create(&SchemaStruct{Name: "other"}).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+	var count int64
+	if err := DB.Table("GormOther.schema_structs").Where("name = ?", "other").Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("row should be created in GormOther, got %v, %v", count, err)
+	}
+	var result SchemaStruct
+	if err := db.First(&result, "name = ?", "other").Error; err != nil {
+		t.Errorf("row should be found in GormOther, got %v", err)
+	}
+}
+
+func TestMigrateCurrentDatabase(t *testing.T) {
+	if name := DB.Migrator().CurrentDatabase(); name != "USER" {
+		t.Errorf("current database should be USER, got %q", name)
+	}
+
+	dialector, ok := DB.Dialector.(*iris.Dialector)
+	if !ok {
+		t.Fatalf("unexpected dialector %#v", DB.Dialector)
+	}
+	namespaces, err := dialector.Namespaces(DB)
+	if err != nil {
+		t.Fatalf("failed to list namespaces, got %v", err)
+	}
+	var found bool
+	for _, namespace := range namespaces {
+		found = found || namespace == "USER"
+	}
+	if !found {
+		t.Errorf("namespaces should include USER, got %v", namespaces)
+	}
+}
+
+func TestMigrateAlterColumn(t *testing.T) {
+	type AlterStruct struct {
+		ID     uint
+		Name   string `gorm:"size:50"`
+		Status string `gorm:"size:10;not null;default:'new'"`
+	}
+
+	DB.Migrator().DropTable(&AlterStruct{})
+	if err := DB.AutoMigrate(&AlterStruct{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+	if err := DB.Create(&AlterStruct{Name: "name", Status: "old"}).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	type AlterStruct2 struct {
+		ID     uint
+		Name   string `gorm:"size:100;not null"`
+		Status string `gorm:"size:20"`
+	}
+
+	if err := DB.Table("alter_structs").AutoMigrate(&AlterStruct2{}); err != nil {
+		t.Fatalf("failed to alter columns, got %v", err)
+	}
+
+	columnTypes, err := DB.Table("alter_structs").Migrator().ColumnTypes(&AlterStruct2{})
+	if err != nil {
+		t.Fatalf("failed to get column types, got error: %v", err)
+	}
+	for _, columnType := range columnTypes {
+		switch columnType.Name() {
+		case "name":
+			if length, _ := columnType.Length(); length != 100 {
+				t.Errorf("column name length should be 100, got %v", length)
+			}
+			if nullable, ok := columnType.Nullable(); !ok || nullable {
+				t.Errorf("column name should not be nullable")
+			}
+		case "status":
+			if length, _ := columnType.Length(); length != 20 {
+				t.Errorf("column status length should be 20, got %v", length)
+			}
+			if nullable, ok := columnType.Nullable(); !ok || !nullable {
+				t.Errorf("column status should be nullable")
+			}
+			if value, ok := columnType.DefaultValue(); ok {
+				t.Errorf("column status should have no default, got %v", value)
+			}
+		}
+	}
+
+	var result AlterStruct2
+	if err := DB.Table("alter_structs").First(&result).Error; err != nil || result.Name != "name" || result.Status != "old" {
+		t.Errorf("data should be kept, got %#v, %v", result, err)
+	}
+
+	alters := collectSQL(t, DB)
+	if err := DB.Table("alter_structs").Migrator().AlterColumn(&AlterStruct2{}, "Name"); err != nil {
+		t.Errorf("failed to alter column, got %v", err)
+	}
+	for _, alter := range *alters {
+		if strings.Contains(alter, "varchar(100)") {
+			t.Errorf("column with the same type should not be altered, got %v", alter)
+		}
+	}
+
+	type AlterStruct3 struct {
+		ID   uint
+		Name int
+	}
+	if err := DB.Table("alter_structs").Migrator().AlterColumn(&AlterStruct3{}, "Name"); err == nil {
+		t.Errorf("converting text to integer should fail")
+	} else if !strings.Contains(err.Error(), "cannot convert column name") {
+		t.Errorf("error should tell which column cannot be converted, got %v", err)
+	}
+}
+
+func TestMigrateRenameDryRun(t *testing.T) {
+	type RenameStruct struct {
+		ID   uint
+		Name string `gorm:"size:100;index:idx_rename_structs_new_name"`
+	}
+
+	db := DB.Session(&gorm.Session{DryRun: true})
+	statements := collectSQL(t, db)
+
+	tests := []struct {
+		name   string
+		rename func(gorm.Migrator) error
+		sql    []string
+	}{
+		{
+			name: "column",
+			rename: func(m gorm.Migrator) error {
+				return m.RenameColumn(&RenameStruct{}, "old_name", "Name")
+			},
+			sql: []string{`ALTER TABLE "rename_structs" ALTER COLUMN "old_name" RENAME "name"`},
+		},
+		{
+			name: "table",
+			rename: func(m gorm.Migrator) error {
+				return m.RenameTable("old_rename_structs", &RenameStruct{})
+			},
+			sql: []string{`ALTER TABLE "old_rename_structs" RENAME "rename_structs"`},
+		},
+		{
+			name: "index",
+			rename: func(m gorm.Migrator) error {
+				return m.RenameIndex(&RenameStruct{}, "idx_rename_structs_old_name", "idx_rename_structs_new_name")
+			},
+			sql: []string{
+				`DROP INDEX "idx_rename_structs_old_name" ON "rename_structs"`,
+				`CREATE INDEX "idx_rename_structs_new_name" ON "rename_structs"("name")`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			*statements = nil
+			if err := test.rename(db.Migrator()); err != nil {
+				t.Fatalf("failed to rename %v, got %v", test.name, err)
+			}
+			if strings.Join(*statements, ";\n") != strings.Join(test.sql, ";\n") {
+				t.Errorf("rename %v should execute %q, got %q", test.name, test.sql, *statements)
+			}
+		})
+	}
+}
+
+func TestMigrateRenameIndex(t *testing.T) {
+	type RenameIndexStruct struct {
+		ID   uint
+		Name string `gorm:"size:100;uniqueIndex:idx_rename_index_structs_old"`
+	}
+
+	DB.Migrator().DropTable(&RenameIndexStruct{})
+	if err := DB.AutoMigrate(&RenameIndexStruct{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	if err := DB.Migrator().RenameIndex(&RenameIndexStruct{}, "idx_rename_index_structs_old", "idx_rename_index_structs_new"); err != nil {
+		t.Fatalf("failed to rename index, got %v", err)
+	}
+	if DB.Migrator().HasIndex(&RenameIndexStruct{}, "idx_rename_index_structs_old") {
+		t.Errorf("index idx_rename_index_structs_old should be dropped")
+	}
+
+	indexes, err := DB.Migrator().GetIndexes(&RenameIndexStruct{})
+	if err != nil {
+		t.Fatalf("failed to get indexes, got %v", err)
+	}
+	var found bool
+	for _, idx := range indexes {
+		if idx.Name() == "idx_rename_index_structs_new" {
+			found = true
+			if unique, _ := idx.Unique(); !unique {
+				t.Errorf("renamed index should stay unique")
+			}
+		}
+	}
+	if !found {
+		t.Errorf("index idx_rename_index_structs_new should exist")
+	}
+}
+
+func TestMigrateCreateTableFeatures(t *testing.T) {
+	type FeatureStruct struct {
+		ID   uint
+		Name string `gorm:"size:100;index"`
+	}
+
+	old := openWithServerVersion(t, "2022.1").Session(&gorm.Session{DryRun: true})
+	statements := collectSQL(t, old)
+	if err := old.Migrator().CreateTable(&FeatureStruct{}); err != nil {
+		t.Fatalf("failed to create table, got %v", err)
+	}
+	if len(*statements) == 0 || !strings.HasPrefix((*statements)[0], `CREATE TABLE "feature_structs" (`) {
+		t.Errorf("table should be created without IF NOT EXISTS, got %v", *statements)
+	}
+	if err := old.Set(iris.ColumnarStorageSetting, true).Migrator().CreateTable(&FeatureStruct{}); err == nil || !strings.Contains(err.Error(), "2023.1") {
+		t.Errorf("columnar storage should not be supported before 2023.1, got %v", err)
+	}
+
+	latest := openWithServerVersion(t, "2025.1").Session(&gorm.Session{DryRun: true})
+	statements = collectSQL(t, latest)
+	if err := latest.Set(iris.ColumnarStorageSetting, true).Migrator().CreateTable(&FeatureStruct{}); err != nil {
+		t.Fatalf("failed to create table, got %v", err)
+	}
+	if len(*statements) == 0 || !strings.HasPrefix((*statements)[0], `CREATE TABLE IF NOT EXISTS "feature_structs" (`) ||
+		!strings.HasSuffix((*statements)[0], "STORAGETYPE = COLUMNAR") {
+		t.Errorf("table should be created with IF NOT EXISTS and columnar storage, got %v", *statements)
+	}
+
+	if dialector, ok := DB.Dialector.(*iris.Dialector); ok && dialector.Supports(iris.FeatureCreateTableIfNotExists) {
+		DB.Migrator().DropTable(&FeatureStruct{})
+		defer DB.Migrator().DropTable(&FeatureStruct{})
+		for i := 0; i < 2; i++ {
+			if err := DB.Set(iris.ColumnarStorageSetting, true).Migrator().CreateTable(&FeatureStruct{}); err != nil {
+				t.Fatalf("failed to create table %v times, got %v", i+1, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package tests_test
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	. "gorm.io/gorm/utils/tests"
+)
+
+func TestReturningCreate(t *testing.T) {
+	users := []User{
+		*GetUser("returning_create_1", Config{}),
+		*GetUser("returning_create_2", Config{}),
+	}
+
+	if err := DB.Clauses(clause.Returning{}).Create(&users).Error; err != nil {
+		t.Fatalf("failed to create with returning, got %v", err)
+	}
+	for _, user := range users {
+		if user.ID == 0 || user.CreatedAt.IsZero() {
+			t.Errorf("returned columns should be set, got %+v", user)
+		}
+	}
+}
+
+func TestReturningUpdate(t *testing.T) {
+	users := []User{
+		*GetUser("returning_update", Config{}),
+		*GetUser("returning_update", Config{}),
+	}
+	DB.Create(&users)
+
+	var updated []User
+	result := DB.Model(&updated).Clauses(clause.Returning{}).Where("name = ?", "returning_update").Update("age", 42)
+	if result.Error != nil {
+		t.Fatalf("failed to update with returning, got %v", result.Error)
+	}
+	if result.RowsAffected != 2 {
+		t.Errorf("rows affected should be 2, got %v", result.RowsAffected)
+	}
+	if len(updated) != 2 {
+		t.Fatalf("updated rows should be returned, got %+v", updated)
+	}
+	for _, user := range updated {
+		if user.Age != 42 || user.Name != "returning_update" {
+			t.Errorf("returned row should be updated, got %+v", user)
+		}
+	}
+
+	var user User
+	DB.First(&user, users[0].ID)
+	result = DB.Model(&user).Clauses(clause.Returning{Columns: []clause.Column{{Name: "age"}}}).Update("age", gorm.Expr("age + 1"))
+	if result.Error != nil {
+		t.Fatalf("failed to update with returning, got %v", result.Error)
+	}
+	if user.Age != 43 {
+		t.Errorf("returned age should be 43, got %v", user.Age)
+	}
+}
+
+func TestReturningDelete(t *testing.T) {
+	users := []User{
+		*GetUser("returning_delete", Config{}),
+		*GetUser("returning_delete", Config{}),
+	}
+	DB.Create(&users)
+
+	var deleted []User
+	result := DB.Unscoped().Clauses(clause.Returning{}).Where("name = ?", "returning_delete").Delete(&deleted)
+	if result.Error != nil {
+		t.Fatalf("failed to delete with returning, got %v", result.Error)
+	}
+	if result.RowsAffected != 2 {
+		t.Errorf("rows affected should be 2, got %v", result.RowsAffected)
+	}
+	if len(deleted) != 2 || deleted[0].ID != users[0].ID || deleted[1].ID != users[1].ID {
+		t.Errorf("deleted rows should be returned, got %+v", deleted)
+	}
+
+	var count int64
+	DB.Unscoped().Model(&User{}).Where("name = ?", "returning_delete").Count(&count)
+	if count != 0 {
+		t.Errorf("rows should be deleted, got %v", count)
+	}
+
+	deleted = nil
+	result = DB.Clauses(clause.Returning{}).Where("name = ?", "returning_delete").Delete(&deleted)
+	if result.Error != nil || result.RowsAffected != 0 || len(deleted) != 0 {
+		t.Errorf("nothing should be deleted, got %+v, %v rows, %v", deleted, result.RowsAffected, result.Error)
+	}
+
+	user := *GetUser("returning_delete_soft", Config{})
+	DB.Create(&user)
+	if err := DB.Clauses(clause.Returning{}).Where("name = ?", user.Name).Delete(&deleted).Error; err != nil {
+		t.Fatalf("failed to soft delete with returning, got %v", err)
+	} else if len(deleted) != 1 || deleted[0].ID != user.ID {
+		t.Errorf("soft deleted row should be returned, got %+v", deleted)
+	}
+}
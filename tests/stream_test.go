@@ -0,0 +1,170 @@
+package tests_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	iris "github.com/caretdev/gorm-iris"
+	"gorm.io/gorm"
+)
+
+type Document struct {
+	ID      uint
+	Title   string `gorm:"size:100"`
+	Summary string
+	Body    string `gorm:"type:text"`
+	Notes   string `gorm:"type:longtext"`
+	Data    []byte
+}
+
+func TestStreamColumns(t *testing.T) {
+	db, err := gorm.Open(iris.New(iris.Config{DSN: connectionString}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+
+	db.Migrator().DropTable(&Document{})
+	if err := db.AutoMigrate(&Document{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	columnTypes, err := db.Migrator().ColumnTypes(&Document{})
+	if err != nil {
+		t.Fatalf("failed to get column types, got %v", err)
+	}
+	expected := map[string]string{"title": "varchar", "summary": "varchar", "body": "longvarchar", "notes": "longvarchar", "data": "longvarbinary"}
+	for _, columnType := range columnTypes {
+		if dataType, ok := expected[columnType.Name()]; ok && columnType.DatabaseTypeName() != dataType {
+			t.Errorf("column %v should be %v, got %v", columnType.Name(), dataType, columnType.DatabaseTypeName())
+		}
+	}
+
+	// longer than the longest string, 3,641,144 characters
+	body := strings.Repeat("0123456789", 400000)
+	data := bytes.Repeat([]byte{0, 1, 2, 254, 255}, 1000)
+	doc := Document{Title: "long", Body: body, Notes: "notes", Data: data}
+	if err := db.Create(&doc).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	var result Document
+	if err := db.First(&result, doc.ID).Error; err != nil {
+		t.Fatalf("failed to find, got %v", err)
+	}
+	if result.Body != body || result.Notes != "notes" || !bytes.Equal(result.Data, data) {
+		t.Errorf("streams should be read back, got body of %v, notes %q, data of %v", len(result.Body), result.Notes, len(result.Data))
+	}
+
+	var notes []string
+	if err := db.Model(&Document{}).Where("id = ?", doc.ID).Pluck("notes", &notes).Error; err != nil || len(notes) != 1 || notes[0] != "notes" {
+		t.Errorf("stream should be plucked, got %v, %v", notes, err)
+	}
+
+	reader := iris.StreamReader(db.Model(&Document{}).Where("id = ?", doc.ID), "body")
+	read, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to read stream, got %v", err)
+	}
+	if string(read) != body {
+		t.Errorf("stream reader should read the whole body, got %v characters", len(read))
+	}
+
+	written := strings.Repeat("é0123456789", 10000)
+	writer := iris.StreamWriter(db.Model(&Document{}).Where("id = ?", doc.ID), "notes")
+	if _, err := io.Copy(writer, strings.NewReader(written)); err != nil {
+		t.Fatalf("failed to write stream, got %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close stream writer, got %v", err)
+	}
+	if err := db.First(&result, doc.ID).Error; err != nil || result.Notes != written {
+		t.Errorf("stream writer should write the whole notes, got %v characters, %v", len(result.Notes), err)
+	}
+
+	binaryReader := iris.StreamReader(db.Model(&Document{}).Where("id = ?", doc.ID), "data")
+	if read, err := io.ReadAll(binaryReader); err != nil || !bytes.Equal(read, data) {
+		t.Errorf("stream reader should read the whole data, got %v bytes, %v", len(read), err)
+	}
+
+	alters := collectSQL(t, db)
+	if err := db.AutoMigrate(&Document{}); err != nil {
+		t.Fatalf("failed to migrate again, got %v", err)
+	} else if len(*alters) != 0 {
+		t.Errorf("migrating unchanged streams should not alter table, got %v", *alters)
+	}
+}
+
+func TestStreamSelectSQL(t *testing.T) {
+	stmt := DB.Session(&gorm.Session{DryRun: true}).Find(&Document{}).Statement
+	if sql := stmt.SQL.String(); !strings.Contains(sql, `SUBSTRING("documents"."notes",1,3641144) AS "notes"`) || strings.Contains(sql, "*") {
+		t.Errorf("stream columns should be selected with SUBSTRING, got %v", sql)
+	}
+}
+
+func TestStreamColumnsKeepVarchar(t *testing.T) {
+	type KeptDocument struct {
+		ID   uint
+		Body string
+	}
+	type Document struct {
+		ID   uint
+		Body string `gorm:"type:text"`
+	}
+
+	DB.Migrator().DropTable(&KeptDocument{})
+	if err := DB.AutoMigrate(&KeptDocument{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	alters := collectSQL(t, DB)
+	if err := DB.Table("kept_documents").AutoMigrate(&Document{}); err != nil {
+		t.Fatalf("failed to migrate to stream, got %v", err)
+	} else if len(*alters) != 0 {
+		t.Errorf("varchar column should be kept, got %v", *alters)
+	}
+}
+
+func TestStreamWriterChunks(t *testing.T) {
+	DB.Migrator().DropTable(&Document{})
+	if err := DB.AutoMigrate(&Document{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+	doc := Document{Title: "chunks"}
+	if err := DB.Create(&doc).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	// the chunks of StreamWriter are 32000 bytes
+	const chunkSize = 32000
+	tests := map[string][]string{
+		"chunk":           {strings.Repeat("a", chunkSize)},
+		"chunk-1":         {strings.Repeat("a", chunkSize-1)},
+		"chunk+1":         {strings.Repeat("a", chunkSize+1)},
+		"chunk,1":         {strings.Repeat("a", chunkSize), "b"},
+		"2 chunks":        {strings.Repeat("a", chunkSize), strings.Repeat("b", chunkSize)},
+		"rune on chunk":   {strings.Repeat("a", chunkSize-1) + "éb"},
+		"rune split":      {strings.Repeat("a", chunkSize-1) + "\xc3", "\xa9b"},
+		"rune after":      {strings.Repeat("a", chunkSize) + "é"},
+		"runes on chunks": {strings.Repeat("é", chunkSize)},
+	}
+	for name, writes := range tests {
+		t.Run(name, func(t *testing.T) {
+			writer := iris.StreamWriter(DB.Model(&Document{}).Where("id = ?", doc.ID), "notes")
+			for _, s := range writes {
+				if _, err := writer.Write([]byte(s)); err != nil {
+					t.Fatalf("failed to write stream, got %v", err)
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("failed to close stream writer, got %v", err)
+			}
+
+			read, err := io.ReadAll(iris.StreamReader(DB.Model(&Document{}).Where("id = ?", doc.ID), "notes"))
+			if expected := strings.Join(writes, ""); err != nil || string(read) != expected {
+				t.Errorf("stream should be written whole, expects %v bytes, got %v bytes, %v", len(expected), len(read), err)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package tests_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	iris "github.com/caretdev/gorm-iris"
+	"gorm.io/gorm"
+)
+
+type Schedule struct {
+	ID        uint
+	Name      string    `gorm:"size:100"`
+	StartedAt time.Time `gorm:"precision:6"`
+	Day       time.Time `gorm:"type:date"`
+	At        time.Time `gorm:"type:time"`
+	EndedAt   *time.Time
+}
+
+func TestTimeTypes(t *testing.T) {
+	DB.Migrator().DropTable(&Schedule{})
+	if err := DB.AutoMigrate(&Schedule{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	columnTypes, err := DB.Migrator().ColumnTypes(&Schedule{})
+	if err != nil {
+		t.Fatalf("failed to get column types, got %v", err)
+	}
+	expected := map[string]string{"started_at": "timestamp", "day": "date", "at": "time", "ended_at": "timestamp"}
+	for _, columnType := range columnTypes {
+		if dataType, ok := expected[columnType.Name()]; ok && columnType.DatabaseTypeName() != dataType {
+			t.Errorf("column %v should be %v, got %v", columnType.Name(), dataType, columnType.DatabaseTypeName())
+		}
+	}
+
+	tokyo := time.FixedZone("JST", 9*60*60)
+	startedAt := time.Date(2024, 3, 1, 8, 30, 15, 123456000, tokyo)
+	schedule := Schedule{Name: "standup", StartedAt: startedAt, Day: startedAt, At: startedAt}
+	if err := DB.Create(&schedule).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	var result Schedule
+	if err := DB.First(&result, schedule.ID).Error; err != nil {
+		t.Fatalf("failed to find, got %v", err)
+	}
+	// timestamps are stored in UTC, so the instant is kept whatever the zone
+	if !result.StartedAt.Equal(startedAt) {
+		t.Errorf("started_at should be %v, got %v", startedAt, result.StartedAt)
+	}
+	// DATE and TIME keep the wall clock of the value
+	if result.Day.Format("2006-01-02") != "2024-03-01" || result.Day.Hour() != 0 {
+		t.Errorf("day should be 2024-03-01, got %v", result.Day)
+	}
+	if result.At.Format("15:04:05") != "08:30:15" {
+		t.Errorf("at should be 08:30:15, got %v", result.At)
+	}
+	if result.EndedAt != nil {
+		t.Errorf("ended_at should be nil, got %v", result.EndedAt)
+	}
+
+	day := time.Date(2024, 3, 2, 23, 0, 0, 0, tokyo)
+	if err := DB.Model(&result).Updates(Schedule{Day: day, At: day}).Error; err != nil {
+		t.Fatalf("failed to update, got %v", err)
+	}
+	if err := DB.First(&result, schedule.ID).Error; err != nil {
+		t.Fatalf("failed to find, got %v", err)
+	} else if result.Day.Format("2006-01-02") != "2024-03-02" || result.At.Format("15:04") != "23:00" {
+		t.Errorf("day and at should be updated to 2024-03-02 23:00, got %v, %v", result.Day, result.At)
+	}
+}
+
+func TestTimeSQL(t *testing.T) {
+	db := DB.Session(&gorm.Session{DryRun: true})
+	stmt := db.Find(&[]Schedule{}).Statement
+	for _, expected := range []string{
+		`CAST("schedules"."day" AS TIMESTAMP) AS "day"`,
+		`CAST({fn CONCAT('1970-01-01 ',CAST("schedules"."at" AS VARCHAR(20)))} AS TIMESTAMP) AS "at"`,
+		`"schedules"."started_at"`,
+	} {
+		if sql := stmt.SQL.String(); !strings.Contains(sql, expected) {
+			t.Errorf("select should contain %v, got %v", expected, sql)
+		}
+	}
+
+	day := time.Date(2024, 3, 1, 23, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	stmt = db.Create(&Schedule{Day: day, At: day}).Statement
+	if sql := stmt.SQL.String(); !strings.Contains(sql, "CAST(? AS DATE),CAST(? AS TIME)") {
+		t.Errorf("date and time should be cast, got %v", sql)
+	}
+	var found bool
+	for _, v := range stmt.Vars {
+		found = found || v == "2024-03-01"
+	}
+	if !found {
+		t.Errorf("date should be passed as 2024-03-01, got %v", stmt.Vars)
+	}
+}
+
+func TestTimePOSIXTime(t *testing.T) {
+	type PosixEvent struct {
+		ID        uint
+		HappendAt time.Time
+	}
+
+	db, err := gorm.Open(iris.New(iris.Config{DSN: connectionString, POSIXTime: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+
+	db.Migrator().DropTable(&PosixEvent{})
+	if err := db.AutoMigrate(&PosixEvent{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	happendAt := time.Date(2024, 3, 1, 8, 30, 15, 123456000, time.FixedZone("EST", -5*60*60))
+	event := PosixEvent{HappendAt: happendAt}
+	if err := db.Create(&event).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	var result PosixEvent
+	if err := db.First(&result, event.ID).Error; err != nil {
+		t.Fatalf("failed to find, got %v", err)
+	} else if !result.HappendAt.Equal(happendAt) {
+		t.Errorf("happend_at should be %v, got %v", happendAt, result.HappendAt)
+	}
+
+	alters := collectSQL(t, db)
+	if err := db.AutoMigrate(&PosixEvent{}); err != nil {
+		t.Fatalf("failed to migrate again, got %v", err)
+	} else if len(*alters) != 0 {
+		t.Errorf("migrating unchanged POSIXTIME should not alter table, got %v", *alters)
+	}
+}
@@ -2,10 +2,13 @@ package tests_test
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	iris "github.com/caretdev/gorm-iris"
 	"gorm.io/gorm"
 	. "gorm.io/gorm/utils/tests"
 )
@@ -490,3 +493,82 @@ func TestTransactionWithDefaultTimeout(t *testing.T) {
 		t.Errorf("should return error when transaction timeout, got error %v", err)
 	}
 }
+
+func TestTransactionSavePointErrors(t *testing.T) {
+	tx := DB.Begin()
+	defer tx.Rollback()
+
+	if err := tx.SavePoint("sp; DROP TABLE users").Error; err == nil {
+		t.Errorf("invalid savepoint name should be rejected")
+	}
+
+	tx2 := DB.Begin()
+	defer tx2.Rollback()
+	if err := tx2.RollbackTo("missing_save_point").Error; err == nil {
+		t.Errorf("rollback to a missing savepoint should fail")
+	}
+
+	dialector, ok := DB.Dialector.(*iris.Dialector)
+	if !ok {
+		t.Fatalf("unexpected dialector %#v", DB.Dialector)
+	}
+	if err := dialector.ReleaseSavePoint(tx2, "save_point1"); err != nil {
+		t.Errorf("release savepoint should succeed, got %v", err)
+	}
+	if err := dialector.ReleaseSavePoint(tx2, "1'; --"); err == nil {
+		t.Errorf("invalid savepoint name should be rejected on release")
+	}
+}
+
+func TestTransactionIsolationLevels(t *testing.T) {
+	for _, level := range []sql.IsolationLevel{sql.LevelReadUncommitted, sql.LevelReadCommitted, iris.LevelReadVerified} {
+		user := *GetUser("isolation_"+level.String(), Config{})
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			return tx.Create(&user).Error
+		}, &sql.TxOptions{Isolation: level})
+		if err != nil {
+			t.Errorf("transaction with isolation level %v should succeed, got %v", level, err)
+		}
+		if err := DB.First(&User{}, "name = ?", user.Name).Error; err != nil {
+			t.Errorf("user of transaction with isolation level %v should be committed, got %v", level, err)
+		}
+	}
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("serializable isolation level should be rejected as not supported, got %v", err)
+	}
+}
+
+func TestTransactionReadOnly(t *testing.T) {
+	user := *GetUser("read_only", Config{})
+	DB.Create(&user)
+
+	tx := DB.Begin(&sql.TxOptions{ReadOnly: true})
+	if err := tx.Error; err != nil {
+		t.Fatalf("read-only transaction should begin, got %v", err)
+	}
+	var result User
+	if err := tx.First(&result, user.ID).Error; err != nil {
+		t.Errorf("read-only transaction should read, got %v", err)
+	}
+	if err := tx.Model(&result).Update("age", 99).Error; err == nil {
+		t.Errorf("read-only transaction should not write")
+	}
+	if err := tx.Raw("UPDATE users SET age = 99 WHERE id = ?", user.ID).Scan(&User{}).Error; err == nil {
+		t.Errorf("read-only transaction should not write with raw query")
+	}
+	if err := tx.Session(&gorm.Session{PrepareStmt: true}).Model(&result).Update("age", 99).Error; err == nil {
+		t.Errorf("read-only transaction should not write with prepared statement")
+	}
+	if err := tx.Commit().Error; err != nil {
+		t.Errorf("read-only transaction should commit, got %v", err)
+	}
+
+	DB.First(&result, user.ID)
+	if result.Age == 99 {
+		t.Errorf("read-only transaction should not change rows")
+	}
+}
@@ -0,0 +1,271 @@
+package tests_test
+
+import (
+	"strings"
+	"testing"
+
+	iris "github.com/caretdev/gorm-iris"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	. "gorm.io/gorm/utils/tests"
+)
+
+func TestUpsert(t *testing.T) {
+	lang := Language{Code: "upsert", Name: "Upsert"}
+	if err := DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&lang).Error; err != nil {
+		t.Fatalf("failed to upsert, got %v", err)
+	}
+
+	lang2 := Language{Code: "upsert", Name: "Upsert"}
+	if err := DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&lang2).Error; err != nil {
+		t.Fatalf("failed to upsert, got %v", err)
+	}
+
+	var langs []Language
+	if err := DB.Find(&langs, "code = ?", lang.Code).Error; err != nil {
+		t.Errorf("no error should happen when find languages with code, but got %v", err)
+	} else if len(langs) != 1 {
+		t.Errorf("should only find only 1 languages, but got %+v", langs)
+	}
+
+	lang3 := Language{Code: "upsert", Name: "Upsert"}
+	if err := DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"name": "upsert-new"}),
+	}).Create(&lang3).Error; err != nil {
+		t.Fatalf("failed to upsert, got %v", err)
+	}
+
+	if err := DB.Find(&langs, "code = ?", lang.Code).Error; err != nil {
+		t.Errorf("no error should happen when find languages with code, but got %v", err)
+	} else if len(langs) != 1 {
+		t.Errorf("should only find only 1 languages, but got %+v", langs)
+	} else if langs[0].Name != "upsert-new" {
+		t.Errorf("should update name on conflict, but got name %+v", langs[0].Name)
+	}
+
+	lang = Language{Code: "upsert", Name: "Upsert-Newname"}
+	if err := DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&lang).Error; err != nil {
+		t.Fatalf("failed to upsert, got %v", err)
+	}
+
+	var result Language
+	if err := DB.Find(&result, "code = ?", lang.Code).Error; err != nil || result.Name != lang.Name {
+		t.Fatalf("failed to upsert, got name %v", result.Name)
+	}
+}
+
+func TestUpsertAssignmentColumns(t *testing.T) {
+	langs := []Language{{Code: "upsert-columns-1", Name: "Name1"}, {Code: "upsert-columns-2", Name: "Name2"}}
+	if err := DB.Create(&langs).Error; err != nil {
+		t.Fatalf("failed to create languages, got %v", err)
+	}
+
+	langs = []Language{{Code: "upsert-columns-1", Name: "NewName1"}, {Code: "upsert-columns-3", Name: "Name3"}}
+	result := DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name"}),
+	}).Create(&langs)
+	if result.Error != nil {
+		t.Fatalf("failed to upsert, got %v", result.Error)
+	} else if result.RowsAffected != 2 {
+		t.Errorf("rows affected expects: %v, got %v", 2, result.RowsAffected)
+	}
+
+	var found []Language
+	DB.Where("code LIKE ?", "upsert-columns-%").Order("code").Find(&found)
+	if len(found) != 3 {
+		t.Fatalf("should find 3 languages, got %+v", found)
+	}
+	AssertEqual(t, found[0].Name, "NewName1")
+	AssertEqual(t, found[1].Name, "Name2")
+	AssertEqual(t, found[2].Name, "Name3")
+}
+
+func TestUpsertWhere(t *testing.T) {
+	lang := Language{Code: "upsert-where", Name: "Name"}
+	DB.Create(&lang)
+
+	newLang := Language{Code: "upsert-where", Name: "NewName"}
+	result := DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name"}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Eq{Column: "name", Value: "OtherName"}}},
+	}).Create(&newLang)
+	if result.Error != nil {
+		t.Fatalf("failed to upsert, got %v", result.Error)
+	} else if result.RowsAffected != 0 {
+		t.Errorf("should not update when where does not match, got rows affected %v", result.RowsAffected)
+	}
+
+	var found Language
+	if err := DB.First(&found, "code = ?", lang.Code).Error; err != nil {
+		t.Fatalf("failed to find language, got %v", err)
+	}
+	AssertEqual(t, found.Name, "Name")
+}
+
+func TestUpsertOtherKey(t *testing.T) {
+	type UpsertKey struct {
+		Code string `gorm:"size:50;primaryKey"`
+		Name string `gorm:"size:50;unique"`
+		Note string `gorm:"size:50"`
+	}
+
+	DB.Migrator().DropTable(&UpsertKey{})
+	if err := DB.AutoMigrate(&UpsertKey{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+	if err := DB.Create(&UpsertKey{Code: "upsert-key", Name: "name", Note: "note"}).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	// conflicts on the primary key, not on name
+	err := DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"note"}),
+	}).Create(&UpsertKey{Code: "upsert-key", Name: "other-name", Note: "new-note"}).Error
+	if err == nil {
+		t.Errorf("conflict on another key should fail")
+	}
+
+	var found UpsertKey
+	if err := DB.First(&found, "code = ?", "upsert-key").Error; err != nil {
+		t.Fatalf("failed to find, got %v", err)
+	}
+	AssertEqual(t, found.Name, "name")
+	AssertEqual(t, found.Note, "note")
+}
+
+func TestUpsertSlice(t *testing.T) {
+	langs := []Language{
+		{Code: "upsert-slice1", Name: "Upsert-slice1"},
+		{Code: "upsert-slice2", Name: "Upsert-slice2"},
+		{Code: "upsert-slice3", Name: "Upsert-slice3"},
+	}
+	DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&langs)
+
+	var langs2 []Language
+	if err := DB.Find(&langs2, "code LIKE ?", "upsert-slice%").Error; err != nil {
+		t.Errorf("no error should happen when find languages with code, but got %v", err)
+	} else if len(langs2) != 3 {
+		t.Errorf("should only find only 3 languages, but got %+v", langs2)
+	}
+
+	DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&langs)
+	var langs3 []Language
+	if err := DB.Find(&langs3, "code LIKE ?", "upsert-slice%").Error; err != nil {
+		t.Errorf("no error should happen when find languages with code, but got %v", err)
+	} else if len(langs3) != 3 {
+		t.Errorf("should only find only 3 languages, but got %+v", langs3)
+	}
+
+	for idx, lang := range langs {
+		lang.Name = lang.Name + "_new"
+		langs[idx] = lang
+	}
+
+	if err := DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name"}),
+	}).Create(&langs).Error; err != nil {
+		t.Fatalf("failed to upsert, got %v", err)
+	}
+
+	for _, lang := range langs {
+		var results []Language
+		if err := DB.Find(&results, "code = ?", lang.Code).Error; err != nil {
+			t.Errorf("no error should happen when find languages with code, but got %v", err)
+		} else if len(results) != 1 {
+			t.Errorf("should only find only 1 languages, but got %+v", langs)
+		} else if results[0].Name != lang.Name {
+			t.Errorf("should update name on conflict, but got name %+v", results[0].Name)
+		}
+	}
+}
+
+func TestUpsertWithSave(t *testing.T) {
+	langs := []Language{
+		{Code: "upsert-save-1", Name: "Upsert-save-1"},
+		{Code: "upsert-save-2", Name: "Upsert-save-2"},
+	}
+
+	if err := DB.Save(&langs).Error; err != nil {
+		t.Errorf("Failed to create, got error %v", err)
+	}
+
+	for idx, lang := range langs {
+		lang.Name += "_new"
+		langs[idx] = lang
+	}
+
+	if err := DB.Session(&gorm.Session{}).Save(&langs).Error; err != nil {
+		t.Errorf("Failed to upsert, got error %v", err)
+	}
+
+	for _, lang := range langs {
+		var result Language
+		if err := DB.First(&result, "code = ?", lang.Code).Error; err != nil {
+			t.Errorf("Failed to query lang, got error %v", err)
+		} else {
+			AssertEqual(t, result, lang)
+		}
+	}
+}
+
+func TestUpsertExcludedExpr(t *testing.T) {
+	lang := Language{Code: "upsert-expr", Name: "Name"}
+	DB.Create(&lang)
+
+	newLang := Language{Code: "upsert-expr", Name: "NewName"}
+	if err := DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoUpdates: clause.Set{{Column: clause.Column{Name: "name"}, Value: gorm.Expr("excluded.name || ?", "!")}},
+	}).Create(&newLang).Error; err != nil {
+		t.Fatalf("failed to upsert, got %v", err)
+	}
+
+	var found Language
+	if err := DB.First(&found, "code = ?", lang.Code).Error; err != nil {
+		t.Fatalf("failed to find language, got %v", err)
+	}
+	AssertEqual(t, found.Name, "NewName!")
+
+	err := DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoUpdates: clause.Set{{Column: clause.Column{Name: "name"}, Value: gorm.Expr("excluded.no_such_column")}},
+	}).Create(&Language{Code: "upsert-expr", Name: "Other"}).Error
+	if err == nil {
+		t.Errorf("unknown excluded column should be rejected")
+	}
+}
+
+func TestUpsertWithInsertOrUpdateConfig(t *testing.T) {
+	db, err := gorm.Open(iris.New(iris.Config{DSN: connectionString, InsertOrUpdate: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+
+	lang := Language{Code: "upsert-config", Name: "Name"}
+	db.Create(&lang)
+
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&lang)
+	})
+	if !strings.HasPrefix(sql, "INSERT INTO ") {
+		t.Errorf("explicit ON CONFLICT should not be INSERT OR UPDATE, got %v", sql)
+	}
+
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&Language{Code: "upsert-config", Name: "NewName"}).Error; err != nil {
+		t.Fatalf("failed to upsert, got %v", err)
+	}
+	var found Language
+	db.First(&found, "code = ?", lang.Code)
+	AssertEqual(t, found.Name, "Name")
+
+	if err := db.Create(&Language{Code: "upsert-config", Name: "NewName"}).Error; err != nil {
+		t.Fatalf("failed to insert or update, got %v", err)
+	}
+	db.First(&found, "code = ?", lang.Code)
+	AssertEqual(t, found.Name, "NewName")
+}
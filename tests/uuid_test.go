@@ -0,0 +1,86 @@
+package tests_test
+
+import (
+	"strings"
+	"testing"
+
+	iris "github.com/caretdev/gorm-iris"
+	"gorm.io/gorm"
+)
+
+type Token struct {
+	ID    iris.UUID `gorm:"primaryKey;default:gen_random_uuid()"`
+	Name  string    `gorm:"size:100"`
+	Owner iris.UUID
+}
+
+func TestUUID(t *testing.T) {
+	DB.Migrator().DropTable(&Token{})
+	if err := DB.AutoMigrate(&Token{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	owner, err := iris.NewUUID()
+	if err != nil {
+		t.Fatalf("failed to generate UUID, got %v", err)
+	}
+	tokens := []Token{{Name: "a", Owner: owner}, {Name: "b"}}
+	if err := DB.Create(&tokens[0]).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+	rest := tokens[1:]
+	if err := DB.Create(&rest).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+	for _, token := range tokens {
+		if token.ID == (iris.UUID{}) {
+			t.Errorf("key of %v should be generated by the server", token.Name)
+		}
+	}
+	if tokens[0].ID == tokens[1].ID {
+		t.Errorf("generated keys should differ, got %v", tokens[0].ID)
+	}
+
+	var result Token
+	if err := DB.First(&result, "id = ?", tokens[0].ID).Error; err != nil {
+		t.Fatalf("failed to find by UUID, got %v", err)
+	} else if result.Name != "a" || result.Owner != owner {
+		t.Errorf("token should be read back, got %+v", result)
+	}
+
+	if err := DB.First(&result, "id = ?", tokens[1].ID).Error; err != nil {
+		t.Fatalf("failed to find by UUID, got %v", err)
+	} else if result.Owner != (iris.UUID{}) {
+		t.Errorf("zero UUID should be stored as NULL, got %v", result.Owner)
+	}
+
+	alters := collectSQL(t, DB)
+	if err := DB.AutoMigrate(&Token{}); err != nil {
+		t.Fatalf("failed to migrate again, got %v", err)
+	} else if len(*alters) != 0 {
+		t.Errorf("migrating unchanged UUID columns should not alter table, got %v", *alters)
+	}
+}
+
+func TestUUIDSQL(t *testing.T) {
+	db := DB.Session(&gorm.Session{DryRun: true})
+	statements := collectSQL(t, db)
+
+	if err := db.Migrator().CreateTable(&Token{}); err != nil {
+		t.Fatalf("failed to create table, got %v", err)
+	}
+	if len(*statements) == 0 || !strings.Contains((*statements)[0], `"id" UNIQUEIDENTIFIER DEFAULT $SYSTEM.Util.CreateGUID()`) {
+		t.Errorf("id should be UNIQUEIDENTIFIER generated by the server, got %v", *statements)
+	}
+
+	for _, s := range []string{"6ba7b810-9dad-11d1-80b4-00c04fd430c8", "{6BA7B810-9DAD-11D1-80B4-00C04FD430C8}", "6ba7b8109dad11d180b400c04fd430c8"} {
+		var u iris.UUID
+		if err := u.Scan(s); err != nil || u.String() != "6BA7B810-9DAD-11D1-80B4-00C04FD430C8" {
+			t.Errorf("UUID %v should be scanned, got %v, %v", s, u, err)
+		}
+	}
+	var u iris.UUID
+	if err := u.Scan("not-a-uuid"); err == nil {
+		t.Errorf("invalid UUID should not be scanned")
+	}
+}
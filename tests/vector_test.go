@@ -0,0 +1,83 @@
+package tests_test
+
+import (
+	"strings"
+	"testing"
+
+	iris "github.com/caretdev/gorm-iris"
+	"gorm.io/gorm"
+)
+
+type Embedding struct {
+	ID     uint
+	Text   string      `gorm:"size:100"`
+	Vector iris.Vector `gorm:"type:vector(double,3)"`
+}
+
+func TestVector(t *testing.T) {
+	if dialector, ok := DB.Dialector.(*iris.Dialector); !ok || !dialector.Supports(iris.FeatureVector) {
+		t.Skip("VECTOR is not supported by the server")
+	}
+
+	DB.Migrator().DropTable(&Embedding{})
+	if err := DB.AutoMigrate(&Embedding{}); err != nil {
+		t.Fatalf("failed to migrate, got %v", err)
+	}
+
+	embeddings := []Embedding{
+		{Text: "x", Vector: iris.Vector{1, 0, 0}},
+		{Text: "y", Vector: iris.Vector{0, 1, 0}},
+		{Text: "xy", Vector: iris.Vector{0.7, 0.7, 0}},
+	}
+	if err := DB.Create(&embeddings).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+
+	var result Embedding
+	if err := DB.First(&result, embeddings[2].ID).Error; err != nil {
+		t.Fatalf("failed to find, got %v", err)
+	} else if len(result.Vector) != 3 || result.Vector[0] != 0.7 || result.Vector[2] != 0 {
+		t.Errorf("vector should be read back, got %v", result.Vector)
+	}
+
+	var nearest []Embedding
+	if err := DB.Scopes(iris.VectorTopK("vector", iris.Vector{0.9, 0.1, 0}, 2)).Find(&nearest).Error; err != nil {
+		t.Fatalf("failed to find nearest, got %v", err)
+	} else if len(nearest) != 2 || nearest[0].Text != "x" || nearest[1].Text != "xy" {
+		t.Errorf("nearest should be x and xy, got %v", nearest)
+	}
+
+	var scores []float64
+	if err := DB.Model(&Embedding{}).Select("?", iris.VectorCosine("vector", iris.Vector{0, 1, 0})).
+		Clauses(iris.OrderByDotProduct("vector", iris.Vector{0, 1, 0})).Scan(&scores).Error; err != nil {
+		t.Fatalf("failed to select similarity, got %v", err)
+	} else if len(scores) != 3 || scores[0] < 0.99 {
+		t.Errorf("similarity of y should be 1, got %v", scores)
+	}
+}
+
+func TestVectorSQL(t *testing.T) {
+	stmt := DB.Session(&gorm.Session{DryRun: true}).Scopes(iris.VectorTopK("vector", iris.Vector{1, 0.5}, 5)).Find(&[]Embedding{}).Statement
+	sql := stmt.SQL.String()
+	if !strings.Contains(sql, `ORDER BY VECTOR_COSINE("vector",TO_VECTOR(?,DOUBLE)) DESC`) {
+		t.Errorf("nearest rows should be ordered by cosine similarity, got %v", sql)
+	}
+	if len(stmt.Vars) == 0 || stmt.Vars[0] != "1,0.5" {
+		t.Errorf("vector should be passed as a string, got %v", stmt.Vars)
+	}
+
+	var vector iris.Vector
+	if err := vector.Scan("[1.5,-2,3e-3]"); err != nil || len(vector) != 3 || vector[1] != -2 || vector[2] != 0.003 {
+		t.Errorf("vector should be scanned, got %v, %v", vector, err)
+	}
+}
+
+func TestVectorNotSupported(t *testing.T) {
+	db := openWithServerVersion(t, "2023.1")
+	if err := db.Migrator().CreateTable(&Embedding{}); err == nil || !strings.Contains(err.Error(), "2024.1") {
+		t.Errorf("VECTOR column should not be created before 2024.1, got %v", err)
+	}
+	if err := db.Table("embeddings").Migrator().AddColumn(&Embedding{}, "Vector"); err == nil {
+		t.Errorf("VECTOR column should not be added before 2024.1")
+	}
+}
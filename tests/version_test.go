@@ -0,0 +1,59 @@
+package tests_test
+
+import (
+	"testing"
+
+	iris "github.com/caretdev/gorm-iris"
+)
+
+func TestServerVersion(t *testing.T) {
+	dialector, ok := DB.Dialector.(*iris.Dialector)
+	if !ok {
+		t.Fatalf("unexpected dialector %T", DB.Dialector)
+	}
+
+	if dialector.ServerVersion == "" {
+		t.Fatalf("server version should be detected on initialize")
+	}
+
+	version, ok := dialector.Version()
+	if !ok {
+		t.Fatalf("failed to parse server version %q", dialector.ServerVersion)
+	} else if !version.AtLeast(2022, 1) {
+		t.Errorf("server version should be at least 2022.1, got %v", version)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	for str, expected := range map[string]iris.Version{
+		"2024.1":   {Year: 2024, Release: 1},
+		"2022.1.2": {Year: 2022, Release: 1, Maintenance: 2},
+		"IRIS for UNIX (Ubuntu Server LTS for x86-64 Containers) 2025.1 (Build 223U) Tue Mar 11 2025 18:13:59 EDT": {Year: 2025, Release: 1},
+	} {
+		if version, err := iris.ParseVersion(str); err != nil {
+			t.Errorf("failed to parse %q, got error %v", str, err)
+		} else if version != expected {
+			t.Errorf("parsed %q expects %v, got %v", str, expected, version)
+		}
+	}
+
+	if _, err := iris.ParseVersion("unknown"); err == nil {
+		t.Errorf("should fail to parse unknown version")
+	}
+
+	if !(iris.Version{Year: 2024, Release: 1}).AtLeast(2023, 1) || (iris.Version{Year: 2023, Release: 1}).AtLeast(2024, 1) {
+		t.Errorf("versions should be compared by year and release")
+	}
+}
+
+func TestSupportsFeature(t *testing.T) {
+	old := iris.Dialector{Config: &iris.Config{ServerVersion: "2022.1"}}
+	if old.Supports(iris.FeatureVector) {
+		t.Errorf("2022.1 should not support VECTOR")
+	}
+
+	latest := iris.Dialector{Config: &iris.Config{ServerVersion: "2025.1"}}
+	if !latest.Supports(iris.FeatureVector) || !latest.Supports(iris.FeatureLimitOffset) {
+		t.Errorf("2025.1 should support VECTOR and LIMIT")
+	}
+}
@@ -0,0 +1,96 @@
+package tests_test
+
+import (
+	"testing"
+
+	"gorm.io/gorm/clause"
+	. "gorm.io/gorm/utils/tests"
+)
+
+func TestWhereMultiColumnIN(t *testing.T) {
+	users := []User{
+		*GetUser("where_in_1", Config{}),
+		*GetUser("where_in_2", Config{}),
+		*GetUser("where_in_3", Config{}),
+	}
+	DB.Create(&users)
+
+	var found []User
+	pairs := [][]interface{}{{users[0].ID, users[0].Name}, {users[2].ID, users[2].Name}, {users[1].ID, "other"}}
+	if err := DB.Where("(id, name) IN ?", pairs).Order("id").Find(&found).Error; err != nil {
+		t.Fatalf("failed to query multi-column IN, got %v", err)
+	}
+	if len(found) != 2 || found[0].ID != users[0].ID || found[1].ID != users[2].ID {
+		t.Errorf("rows of matching tuples should be found, got %+v", found)
+	}
+
+	found = nil
+	in := clause.IN{
+		Column: []clause.Column{{Name: "id"}, {Name: "name"}},
+		Values: []interface{}{[]interface{}{users[1].ID, users[1].Name}, []interface{}{users[2].ID, users[2].Name}},
+	}
+	if err := DB.Where(in).Order("id").Find(&found).Error; err != nil {
+		t.Fatalf("failed to query multi-column IN, got %v", err)
+	}
+	if len(found) != 2 || found[0].ID != users[1].ID || found[1].ID != users[2].ID {
+		t.Errorf("rows of matching tuples should be found, got %+v", found)
+	}
+
+	found = nil
+	if err := DB.Where("(id, name) IN ?", [][]interface{}{}).Find(&found).Error; err != nil {
+		t.Fatalf("failed to query empty multi-column IN, got %v", err)
+	}
+	if len(found) != 0 {
+		t.Errorf("empty multi-column IN should match nothing, got %+v", found)
+	}
+
+	in.Values = nil
+	if err := DB.Where(in).Find(&found).Error; err != nil || len(found) != 0 {
+		t.Errorf("empty multi-column IN should match nothing, got %+v, %v", found, err)
+	}
+}
+
+func TestWhereNestedMultiColumnIN(t *testing.T) {
+	users := []User{
+		*GetUser("where_nested_in_1", Config{}),
+		*GetUser("where_nested_in_2", Config{}),
+		*GetUser("where_nested_in_3", Config{}),
+	}
+	DB.Create(&users)
+	names := []string{users[0].Name, users[1].Name, users[2].Name}
+
+	var found []User
+	pairs := [][]interface{}{{users[1].ID, users[1].Name}}
+	if err := DB.Where("id = ?", users[0].ID).Or("(id, name) IN ?", pairs).Order("id").Find(&found).Error; err != nil {
+		t.Fatalf("failed to query multi-column IN in OR, got %v", err)
+	}
+	if len(found) != 2 || found[0].ID != users[0].ID || found[1].ID != users[1].ID {
+		t.Errorf("rows of OR conditions should be found, got %+v", found)
+	}
+
+	found = nil
+	in := clause.IN{
+		Column: []clause.Column{{Name: "id"}, {Name: "name"}},
+		Values: []interface{}{[]interface{}{users[0].ID, users[0].Name}, []interface{}{users[1].ID, users[1].Name}},
+	}
+	if err := DB.Where("name IN ?", names).Not(in).Find(&found).Error; err != nil {
+		t.Fatalf("failed to query negated multi-column IN, got %v", err)
+	}
+	if len(found) != 1 || found[0].ID != users[2].ID {
+		t.Errorf("rows out of the tuples should be found, got %+v", found)
+	}
+
+	found = nil
+	if err := DB.Where("name IN ?", names).Where("(id, name) NOT IN ?", pairs).Find(&found).Error; err != nil {
+		t.Fatalf("failed to query multi-column NOT IN, got %v", err)
+	}
+	if len(found) != 2 {
+		t.Errorf("rows out of the tuples should be found, got %+v", found)
+	}
+
+	found = nil
+	in.Values = nil
+	if err := DB.Where("name IN ?", names).Not(in).Find(&found).Error; err != nil || len(found) != 3 {
+		t.Errorf("negated empty multi-column IN should match everything, got %+v, %v", found, err)
+	}
+}
@@ -0,0 +1,283 @@
+package iris
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// LevelReadVerified is IRIS READ VERIFIED isolation level, which has no
+// sql.IsolationLevel of its own:
+//
+//	db.Begin(&sql.TxOptions{Isolation: iris.LevelReadVerified})
+const LevelReadVerified = sql.IsolationLevel(100)
+
+// defaultIsolationLevel is IRIS default isolation level, set back at the end
+// of a transaction begun with another one
+const defaultIsolationLevel = "READ UNCOMMITTED"
+
+// defaultCommitMode is the %COMMITMODE of the connections of the driver,
+// which log in with autocommit, set back at the end of a transaction begun
+// with an isolation level
+const defaultCommitMode = "IMPLICIT"
+
+var isolationLevels = map[sql.IsolationLevel]string{
+	sql.LevelReadUncommitted: "READ UNCOMMITTED",
+	sql.LevelReadCommitted:   "READ COMMITTED",
+	LevelReadVerified:        "READ VERIFIED",
+}
+
+var errReadOnlyTransaction = errors.New("iris: cannot write in a read-only transaction")
+
+// readOnlyStatements are the first words of the statements allowed in a
+// read-only transaction
+var readOnlyStatements = map[string]bool{
+	"SELECT":    true,
+	"WITH":      true,
+	"SET":       true,
+	"SAVEPOINT": true,
+	"ROLLBACK":  true,
+}
+
+// OpenDB opens a pool of connections to dsn, with the driver registered as
+// driverName, which begin transactions with the options the driver ignores
+// or rejects: the isolation level of sql.TxOptions is set with SET
+// TRANSACTION once the transaction is started, and statements which may
+// write are refused in a read-only transaction.
+// Initialize opens its pool with OpenDB, a pool given as Config.Conn is used
+// as is, and has to be opened with OpenDB to get these options.
+//
+//	sqlDB, err := iris.OpenDB(iris.DefaultDriverName, dsn)
+//	db, err := gorm.Open(iris.New(iris.Config{Conn: tracing.Wrap(sqlDB)}), &gorm.Config{})
+func OpenDB(driverName, dsn string) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	d := db.Driver()
+	db.Close()
+
+	var connector driver.Connector = dsnConnector{driver: d, dsn: dsn}
+	if driverContext, ok := d.(driver.DriverContext); ok {
+		if connector, err = driverContext.OpenConnector(dsn); err != nil {
+			return nil, err
+		}
+	}
+	return sql.OpenDB(txConnector{connector}), nil
+}
+
+// dsnConnector is the connector of a driver with no connector of its own
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// txConnector connects with txConn
+type txConnector struct {
+	driver.Connector
+}
+
+func (c txConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &txConn{Conn: conn}, nil
+}
+
+// txConn begins transactions with sql.TxOptions. IRIS has no read-only
+// transactions, so statements which may write are refused when prepared,
+// the way every statement is sent by the driver, or run, for statements
+// prepared before, and Commit rolls back.
+type txConn struct {
+	driver.Conn
+	readOnly  bool
+	isolation bool
+}
+
+func (c *txConn) Prepare(query string) (driver.Stmt, error) {
+	write := !readOnlyStatements[strings.ToUpper(sqlWord(strings.TrimLeft(query, " \t\r\n(")))]
+	if c.readOnly && write {
+		return nil, errReadOnlyTransaction
+	}
+	stmt, err := c.Conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return &txStmt{Stmt: stmt, conn: c, write: write}, nil
+}
+
+// Ping implements driver.Pinger, when the connection of the driver does.
+func (c *txConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession implements driver.SessionResetter, when the connection of
+// the driver does.
+func (c *txConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid implements driver.Validator, when the connection of the driver
+// does.
+func (c *txConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue implements driver.NamedValueChecker, when the connection
+// of the driver does, database/sql converts the value otherwise.
+func (c *txConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+func (c *txConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *txConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var isolation string
+	if level := sql.IsolationLevel(opts.Isolation); level != sql.LevelDefault {
+		var ok bool
+		if isolation, ok = isolationLevels[level]; !ok {
+			return nil, fmt.Errorf("iris: isolation level %s is not supported", level)
+		}
+	}
+
+	// the isolation level is set for the process, before the transaction,
+	// which is explicit so a driver with autocommit can not run the SET
+	// TRANSACTION in a transaction of its own
+	if isolation != "" {
+		for _, query := range []string{"SET TRANSACTION %COMMITMODE EXPLICIT", "SET TRANSACTION ISOLATION LEVEL " + isolation} {
+			if err := c.exec(query); err != nil {
+				c.reset()
+				return nil, err
+			}
+		}
+	}
+
+	var (
+		tx  driver.Tx
+		err error
+	)
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = beginner.BeginTx(ctx, driver.TxOptions{})
+	} else {
+		tx, err = c.Conn.Begin()
+	}
+	if err != nil {
+		if isolation != "" {
+			c.reset()
+		}
+		return nil, err
+	}
+	c.readOnly, c.isolation = opts.ReadOnly, isolation != ""
+	return &txTx{Tx: tx, conn: c}, nil
+}
+
+// reset sets the isolation level and the commit mode of the connection back
+func (c *txConn) reset() error {
+	err := c.exec("SET TRANSACTION ISOLATION LEVEL " + defaultIsolationLevel)
+	if commitModeErr := c.exec("SET TRANSACTION %COMMITMODE " + defaultCommitMode); err == nil {
+		err = commitModeErr
+	}
+	return err
+}
+
+func (c *txConn) exec(query string) error {
+	stmt, err := c.Conn.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(nil)
+	return err
+}
+
+// txStmt is a statement of txConn, refused in a read-only transaction when
+// it may write
+type txStmt struct {
+	driver.Stmt
+	conn  *txConn
+	write bool
+}
+
+// CheckNamedValue implements driver.NamedValueChecker, when the statement
+// of the driver does, the connection checks the value otherwise.
+func (s *txStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return s.conn.CheckNamedValue(value)
+}
+
+func (s *txStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.conn.readOnly && s.write {
+		return nil, errReadOnlyTransaction
+	}
+	return s.Stmt.Exec(args)
+}
+
+func (s *txStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if s.conn.readOnly && s.write {
+		return nil, errReadOnlyTransaction
+	}
+	return s.Stmt.Query(args)
+}
+
+// txTx ends a transaction begun with options
+type txTx struct {
+	driver.Tx
+	conn *txConn
+}
+
+func (t *txTx) Commit() error {
+	if t.conn.readOnly {
+		return t.Rollback()
+	}
+	err := t.Tx.Commit()
+	if endErr := t.end(); err == nil {
+		err = endErr
+	}
+	return err
+}
+
+func (t *txTx) Rollback() error {
+	err := t.Tx.Rollback()
+	if endErr := t.end(); err == nil {
+		err = endErr
+	}
+	return err
+}
+
+// end sets the isolation level and the commit mode back, as they are kept
+// by the connection after the transaction, and ends the read-only mode
+func (t *txTx) end() (err error) {
+	if t.conn.isolation {
+		err = t.conn.reset()
+	}
+	t.conn.readOnly, t.conn.isolation = false, false
+	return err
+}
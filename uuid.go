@@ -0,0 +1,73 @@
+package iris
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// UUID is a UNIQUEIDENTIFIER value, which IRIS stores as a 36 characters
+// string. `gorm:"default:gen_random_uuid()"` lets the server generate it.
+type UUID [16]byte
+
+// NewUUID returns a random, version 4, UUID.
+func NewUUID() (u UUID, err error) {
+	if _, err = rand.Read(u[:]); err != nil {
+		return u, err
+	}
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return u, nil
+}
+
+// ParseUUID parses xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx, with or without
+// dashes and braces.
+func ParseUUID(s string) (u UUID, err error) {
+	h := strings.ReplaceAll(strings.Trim(s, "{}"), "-", "")
+	if len(h) != 32 {
+		return u, fmt.Errorf("iris: invalid UUID %q", s)
+	}
+	if _, err = hex.Decode(u[:], []byte(h)); err != nil {
+		return u, fmt.Errorf("iris: invalid UUID %q", s)
+	}
+	return u, nil
+}
+
+// GormDataType implements schema.GormDataTypeInterface.
+func (UUID) GormDataType() string {
+	return "uuid"
+}
+
+func (u UUID) String() string {
+	h := hex.EncodeToString(u[:])
+	return strings.ToUpper(h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:])
+}
+
+// Value implements driver.Valuer, the zero UUID is NULL.
+func (u UUID) Value() (driver.Value, error) {
+	if u == (UUID{}) {
+		return nil, nil
+	}
+	return u.String(), nil
+}
+
+// Scan implements sql.Scanner.
+func (u *UUID) Scan(value interface{}) (err error) {
+	switch value := value.(type) {
+	case nil:
+		*u = UUID{}
+	case string:
+		*u, err = ParseUUID(value)
+	case []byte:
+		if len(value) == len(u) {
+			copy(u[:], value)
+			return nil
+		}
+		*u, err = ParseUUID(string(value))
+	default:
+		err = fmt.Errorf("iris: cannot scan %T into UUID", value)
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+package iris
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Vector is a VECTOR(DOUBLE, n) value, available since IRIS 2024.1, see
+// FeatureVector. The dimension is taken from the size tag,
+// `gorm:"size:384"`, or given with `gorm:"type:vector(double,384)"`.
+type Vector []float64
+
+// GormDataType implements schema.GormDataTypeInterface.
+func (Vector) GormDataType() string {
+	return "vector"
+}
+
+// GormDBDataType implements migrator.GormDataTypeInterface.
+func (Vector) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	if dataType, ok := field.TagSettings["TYPE"]; ok {
+		return dataType
+	}
+	if field.Size > 0 {
+		return fmt.Sprintf("VECTOR(DOUBLE,%d)", field.Size)
+	}
+	return "VECTOR(DOUBLE)"
+}
+
+// String returns the vector as IRIS writes it, comma separated numbers
+func (v Vector) String() string {
+	var b strings.Builder
+	for i, f := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	return b.String()
+}
+
+// GormValue implements gorm.Valuer, IRIS converts strings to vectors with
+// TO_VECTOR only.
+func (v Vector) GormValue(ctx context.Context, db *gorm.DB) clause.Expr {
+	if v == nil {
+		return clause.Expr{SQL: "NULL"}
+	}
+	return clause.Expr{SQL: "TO_VECTOR(?,DOUBLE)", Vars: []interface{}{v.String()}}
+}
+
+// Value implements driver.Valuer.
+func (v Vector) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return v.String(), nil
+}
+
+// Scan implements sql.Scanner.
+func (v *Vector) Scan(value interface{}) error {
+	var s string
+	switch value := value.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case string:
+		s = value
+	case []byte:
+		s = string(value)
+	default:
+		return fmt.Errorf("iris: cannot scan %T into Vector", value)
+	}
+
+	s = strings.Trim(strings.TrimSpace(s), "[]")
+	if s == "" {
+		*v = Vector{}
+		return nil
+	}
+	items := strings.Split(s, ",")
+	vector := make(Vector, len(items))
+	for i, item := range items {
+		f, err := strconv.ParseFloat(strings.TrimSpace(item), 64)
+		if err != nil {
+			return fmt.Errorf("iris: cannot scan %q into Vector: %w", s, err)
+		}
+		vector[i] = f
+	}
+	*v = vector
+	return nil
+}
+
+// VectorCosine is the cosine similarity of a vector column and a vector,
+// to be selected or ordered by.
+func VectorCosine(column string, vector Vector) clause.Expr {
+	return clause.Expr{SQL: "VECTOR_COSINE(?,?)", Vars: []interface{}{clause.Column{Name: column}, vector}}
+}
+
+// VectorDotProduct is the dot product of a vector column and a vector.
+func VectorDotProduct(column string, vector Vector) clause.Expr {
+	return clause.Expr{SQL: "VECTOR_DOT_PRODUCT(?,?)", Vars: []interface{}{clause.Column{Name: column}, vector}}
+}
+
+// OrderByCosine orders rows from the most similar to vector.
+//
+//	db.Clauses(iris.OrderByCosine("embedding", query)).Limit(5).Find(&docs)
+func OrderByCosine(column string, vector Vector) clause.OrderBy {
+	return clause.OrderBy{Expression: clause.Expr{SQL: "? DESC", Vars: []interface{}{VectorCosine(column, vector)}}}
+}
+
+// OrderByDotProduct orders rows from the highest dot product with vector.
+func OrderByDotProduct(column string, vector Vector) clause.OrderBy {
+	return clause.OrderBy{Expression: clause.Expr{SQL: "? DESC", Vars: []interface{}{VectorDotProduct(column, vector)}}}
+}
+
+// VectorTopK is a scope finding k rows nearest to vector by cosine
+// similarity.
+//
+//	db.Scopes(iris.VectorTopK("embedding", query, 5)).Find(&docs)
+func VectorTopK(column string, vector Vector, k int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Clauses(OrderByCosine(column, vector)).Limit(k)
+	}
+}
@@ -0,0 +1,98 @@
+package iris
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Version is an IRIS release number, like 2024.1 or 2022.1.2
+type Version struct {
+	Year        int
+	Release     int
+	Maintenance int
+}
+
+var versionRegexp = regexp.MustCompile(`(\d{4})\.(\d+)(?:\.(\d+))?`)
+
+// ParseVersion extracts the release number from a version string,
+// either a bare number or the whole $ZVERSION.
+func ParseVersion(version string) (v Version, err error) {
+	matches := versionRegexp.FindStringSubmatch(version)
+	if matches == nil {
+		return v, fmt.Errorf("iris: unrecognized server version %q", version)
+	}
+	v.Year, _ = strconv.Atoi(matches[1])
+	v.Release, _ = strconv.Atoi(matches[2])
+	if matches[3] != "" {
+		v.Maintenance, _ = strconv.Atoi(matches[3])
+	}
+	return v, nil
+}
+
+// Compare returns -1, 0 or +1 depending on whether v is older, the same or
+// newer than other.
+func (v Version) Compare(other Version) int {
+	for _, d := range [...]int{v.Year - other.Year, v.Release - other.Release, v.Maintenance - other.Maintenance} {
+		switch {
+		case d < 0:
+			return -1
+		case d > 0:
+			return 1
+		}
+	}
+	return 0
+}
+
+// AtLeast reports whether v is the same or newer than year.release
+func (v Version) AtLeast(year, release int) bool {
+	return v.Compare(Version{Year: year, Release: release}) >= 0
+}
+
+func (v Version) String() string {
+	if v.Maintenance > 0 {
+		return fmt.Sprintf("%d.%d.%d", v.Year, v.Release, v.Maintenance)
+	}
+	return fmt.Sprintf("%d.%d", v.Year, v.Release)
+}
+
+// Feature is an IRIS SQL capability available since some release
+type Feature int
+
+const (
+	// FeatureLimitOffset is LIMIT n OFFSET m in SELECT
+	FeatureLimitOffset Feature = iota
+	// FeatureVector is VECTOR data type and VECTOR_* functions
+	FeatureVector
+	// FeatureColumnarStorage is WITH STORAGETYPE = COLUMNAR
+	FeatureColumnarStorage
+	// FeatureCreateTableIfNotExists is CREATE TABLE IF NOT EXISTS
+	FeatureCreateTableIfNotExists
+)
+
+var featureVersions = map[Feature]Version{
+	FeatureLimitOffset:            {Year: 2025, Release: 1},
+	FeatureVector:                 {Year: 2024, Release: 1},
+	FeatureColumnarStorage:        {Year: 2023, Release: 1},
+	FeatureCreateTableIfNotExists: {Year: 2023, Release: 1},
+}
+
+// Version returns the version of the connected IRIS, parsed from
+// Config.ServerVersion, ok is false when it is unknown.
+func (dialector Dialector) Version() (v Version, ok bool) {
+	if dialector.Config == nil || dialector.ServerVersion == "" {
+		return v, false
+	}
+	v, err := ParseVersion(dialector.ServerVersion)
+	return v, err == nil
+}
+
+// Supports reports whether the connected IRIS has the feature.
+// With unknown server version the latest release is assumed.
+func (dialector Dialector) Supports(feature Feature) bool {
+	v, ok := dialector.Version()
+	if !ok {
+		return true
+	}
+	return v.Compare(featureVersions[feature]) >= 0
+}